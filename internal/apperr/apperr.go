@@ -0,0 +1,138 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPreconditionFailed
+)
+
+// Error is an error with a kind, used to pick the HTTP status code, and a stable
+// machine readable code the frontend can rely on. Message is safe to show to users.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details []string
+	Err     error
+
+	// Current is the current representation of the resource, sent back when a
+	// precondition failed so the client can merge its changes.
+	Current interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status code for the kind of the error.
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindBadRequest:
+		return http.StatusBadRequest
+	case KindValidation:
+		return http.StatusUnprocessableEntity
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func New(kind Kind, code string, format string, args ...interface{}) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func BadRequest(code string, format string, args ...interface{}) *Error {
+	return New(KindBadRequest, code, format, args...)
+}
+
+func Validation(code string, format string, args ...interface{}) *Error {
+	return New(KindValidation, code, format, args...)
+}
+
+func Unauthorized(code string, format string, args ...interface{}) *Error {
+	return New(KindUnauthorized, code, format, args...)
+}
+
+func Forbidden(code string, format string, args ...interface{}) *Error {
+	return New(KindForbidden, code, format, args...)
+}
+
+func NotFound(code string, format string, args ...interface{}) *Error {
+	return New(KindNotFound, code, format, args...)
+}
+
+func Conflict(code string, format string, args ...interface{}) *Error {
+	return New(KindConflict, code, format, args...)
+}
+
+// PreconditionFailed reports a write based on a stale version of current.
+func PreconditionFailed(code string, current interface{}, format string, args ...interface{}) *Error {
+	e := New(KindPreconditionFailed, code, format, args...)
+	e.Current = current
+
+	return e
+}
+
+// Internal hides err behind a generic message. The cause is kept for logging.
+func Internal(err error, format string, args ...interface{}) *Error {
+	e := New(KindInternal, CodeInternal, format, args...)
+	e.Err = err
+
+	return e
+}
+
+// WithDetails returns a copy of e with the given details attached.
+func (e *Error) WithDetails(details []string) *Error {
+	copied := *e
+	copied.Details = details
+
+	return &copied
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var e *Error
+
+	if errors.As(err, &e) {
+		return e, true
+	}
+
+	return nil, false
+}
+
+// IsKind reports whether err is an *Error of the given kind.
+func IsKind(err error, kind Kind) bool {
+	e, ok := As(err)
+
+	return ok && e.Kind == kind
+}
@@ -0,0 +1,35 @@
+package apperr
+
+// Codes are part of the API contract. Never change the value of an existing code.
+const (
+	CodeInternal     = "INTERNAL_ERROR"
+	CodeRouteMissing = "ROUTE_NOT_FOUND"
+	CodeHTTPError    = "HTTP_ERROR"
+
+	CodeInvalidParam     = "INVALID_PARAM"
+	CodeInvalidPayload   = "INVALID_PAYLOAD"
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInvalidDate      = "INVALID_DATE"
+	CodeInvalidSchedule  = "INVALID_SCHEDULE"
+	CodeInvalidPartCode  = "INVALID_PARTICIPANT"
+
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeInvalidCredentials = "INVALID_CREDENTIALS"
+	CodeInvalidToken       = "INVALID_TOKEN"
+	CodeTokenMismatch      = "TOKEN_MISMATCH"
+
+	CodeFormNotFound      = "FORM_NOT_FOUND"
+	CodeFormNotOpen       = "FORM_NOT_OPEN"
+	CodeFormClosed        = "FORM_CLOSED"
+	CodeNotParticipant    = "NOT_PARTICIPANT"
+	CodeAlreadyFilled     = "ALREADY_FILLED"
+	CodeParticipantLocked = "PARTICIPANT_LOCKED"
+	CodeResultForbidden   = "RESULT_FORBIDDEN"
+	CodeMemberNotFound    = "MEMBER_NOT_FOUND"
+	CodePengurusNotFound  = "PENGURUS_NOT_FOUND"
+	CodeVersionMismatch   = "VERSION_MISMATCH"
+
+	CodeInvalidIdempotencyKey = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+)
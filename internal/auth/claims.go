@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"errors"
+	"himatro-api/internal/config"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Login and update absent list tokens are signed with the same key, so each
+// carries its own audience and is refused where the other one is expected.
+const (
+	audienceLogin            = "himatro-api/login"
+	audienceUpdateAbsentList = "himatro-api/update-absent-list"
+)
+
+// parser leaves the claims to validateClaims, which checks them against the
+// clock of the caller instead of the system time.
+var parser = &jwt.Parser{SkipClaimsValidation: true}
+
+// signingKey is the jwt.Keyfunc of every token, it only accepts HMAC signatures.
+func signingKey(t *jwt.Token) (interface{}, error) {
+	if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return []byte(config.Get().Auth.JWTSigningKey), nil
+}
+
+// validateClaims requires an expiry and the given audience.
+func validateClaims(claims jwt.StandardClaims, audience string, now time.Time) error {
+	unix := now.Unix()
+
+	if !claims.VerifyAudience(audience, true) {
+		return errors.New("token is not meant for this use")
+	}
+
+	if !claims.VerifyExpiresAt(unix, true) {
+		return errors.New("token is expired")
+	}
+
+	if !claims.VerifyIssuedAt(unix, false) {
+		return errors.New("token used before issued")
+	}
+
+	if !claims.VerifyNotBefore(unix, false) {
+		return errors.New("token is not valid yet")
+	}
+
+	return nil
+}
@@ -5,21 +5,22 @@ import (
 	"crypto/cipher"
 	"encoding/base64"
 	"errors"
-	"himatro-api/internal/util"
+	"himatro-api/internal/config"
+	"himatro-api/internal/logger"
 )
 
 func Decrypt(encrypted string) (string, error) {
-	block, err := aes.NewCipher([]byte(secret_key))
+	block, err := aes.NewCipher([]byte(config.Get().Auth.SecretKey))
 
 	if err != nil {
-		util.LogErr("ERROR", "Decrypt failed to create new chiper", err.Error())
+		logger.Default().Error("Decrypt failed to create new chiper", logger.ErrorKey, err)
 		return "", err
 	}
 
 	cipherText, err := decode(encrypted)
 
 	if err != nil {
-		util.LogErr("ERROR", "Decrypt failed to decode", err.Error())
+		logger.Default().Info("Decrypt failed to decode", logger.ErrorKey, err)
 		return "", err
 	}
 
@@ -34,7 +35,7 @@ func decode(encrypted string) ([]byte, error) {
 	data, err := base64.StdEncoding.DecodeString(encrypted)
 
 	if err != nil {
-		util.LogErr("ERROR", "Decode process failed", err.Error())
+		logger.Default().Debug("Decode process failed", logger.ErrorKey, err)
 		return []byte(""), errors.New("authentication process failed")
 	}
 
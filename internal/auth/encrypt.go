@@ -5,17 +5,16 @@ import (
 	"crypto/cipher"
 	"encoding/base64"
 	"himatro-api/internal/config"
-	"himatro-api/internal/util"
+	"himatro-api/internal/logger"
 )
 
-var secret_key = config.SecretKey()
 var bytes = []byte{35, 46, 57, 24, 85, 35, 24, 74, 87, 35, 88, 98, 66, 32, 14, 05}
 
 func Encrypt(plainText string) (string, error) {
-	block, err := aes.NewCipher([]byte(secret_key))
+	block, err := aes.NewCipher([]byte(config.Get().Auth.SecretKey))
 
 	if err != nil {
-		util.LogErr("ERROR", "Encrypt failed to create new chiper", err.Error())
+		logger.Default().Error("Encrypt failed to create new chiper", logger.ErrorKey, err)
 		return "", err
 	}
 
@@ -2,8 +2,9 @@ package auth
 
 import (
 	"errors"
+	"himatro-api/internal/clock"
 	"himatro-api/internal/config"
-	"himatro-api/internal/util"
+	"himatro-api/internal/logger"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -14,24 +15,25 @@ type jwtCustomClaims struct {
 	jwt.StandardClaims
 }
 
-func CreateLoginToken(NPM string) (string, error) {
-	claims := createClaims(NPM)
+func CreateLoginToken(clk clock.Clock, NPM string) (string, error) {
+	claims := createClaims(NPM, clk.Now())
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(config.JWTSigningKey()))
+	signedToken, err := token.SignedString([]byte(config.Get().Auth.JWTSigningKey))
 
 	if err != nil {
-		util.LogErr("ERROR", "Server failed to sign the token string", err.Error())
+		logger.Default().Error("Server failed to sign the token string", logger.NPMKey, NPM, logger.ErrorKey, err)
 		return "", errors.New("server failed to create login token")
 	}
 
 	return signedToken, nil
 }
 
-func createClaims(NPM string) jwtCustomClaims {
+func createClaims(NPM string, now time.Time) jwtCustomClaims {
 	claims := jwtCustomClaims{
 		NPM,
 		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Second * time.Duration(config.LoginTokenExpSec())).Unix(),
+			Audience:  audienceLogin,
+			ExpiresAt: now.Add(time.Second * time.Duration(config.Get().Auth.LoginTokenExpSec)).Unix(),
 			Issuer:    NPM,
 		},
 	}
@@ -3,8 +3,9 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"himatro-api/internal/clock"
 	"himatro-api/internal/config"
-	"himatro-api/internal/util"
+	"himatro-api/internal/logger"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -16,34 +17,37 @@ type UpdateAbsentListClaims struct {
 	jwt.StandardClaims
 }
 
-func CreateUpdateAbsentListToken(absentID int, NPM string) (string, error) {
+func CreateUpdateAbsentListToken(clk clock.Clock, absentID int, NPM string) (string, error) {
 	AbsentID := uint(absentID)
 	claims := UpdateAbsentListClaims{
 		NPM,
 		AbsentID,
 		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Second * time.Duration(config.UpdateAbsentListTokenExpSec())).Unix(),
+			Audience:  audienceUpdateAbsentList,
+			ExpiresAt: clk.Now().Add(time.Second * time.Duration(config.Get().Auth.UpdateAbsentListTokenExpSec)).Unix(),
 			Issuer:    NPM,
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(config.JWTSigningKey()))
+	signedToken, err := token.SignedString([]byte(config.Get().Auth.JWTSigningKey))
 
 	if err != nil {
-		util.LogErr("ERROR", "Server failed to create signed token string", err.Error())
+		logger.Default().Error("Server failed to create signed token string", logger.FormIDKey, absentID, logger.NPMKey, NPM, logger.ErrorKey, err)
 		return "", errors.New("server failed to create update token")
 	}
 
 	return signedToken, nil
 }
 
-func ExtractJWTPayload(token string, claims *UpdateAbsentListClaims) error {
-	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
-		return []byte(config.JWTSigningKey()), nil
-	})
+func ExtractJWTPayload(clk clock.Clock, token string, claims *UpdateAbsentListClaims) error {
+	_, err := parser.ParseWithClaims(token, claims, signingKey)
+
+	if err == nil {
+		err = validateClaims(claims.StandardClaims, audienceUpdateAbsentList, clk.Now())
+	}
 
 	if err != nil {
-		util.LogErr("INFO", fmt.Sprintf("Invalid token used: %s", token), err.Error())
+		logger.Default().Info("Invalid update absent list token used", logger.ErrorKey, err)
 		return fmt.Errorf("invalid token: %s", err.Error())
 	}
 
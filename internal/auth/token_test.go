@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"himatro-api/internal/clock"
+	"himatro-api/internal/config"
+)
+
+func TestLoginTokenExpiry(t *testing.T) {
+	config.Set(config.Default())
+
+	expiry := time.Duration(config.Get().Auth.LoginTokenExpSec) * time.Second
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		valid bool
+	}{
+		{"right away", 0, true},
+		{"just before expiry", expiry - time.Second, true},
+		{"after expiry", expiry + time.Second, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC))
+
+			token, err := CreateLoginToken(clk, "2115061001")
+
+			if err != nil {
+				t.Fatalf("create login token: %v", err)
+			}
+
+			clk.Advance(tt.after)
+
+			err = ValidateLoginToken(clk, token)
+
+			if tt.valid && err != nil {
+				t.Errorf("got %v, want a valid token", err)
+			}
+
+			if !tt.valid && err == nil {
+				t.Error("expired token was accepted")
+			}
+		})
+	}
+}
+
+func TestTokensAreNotInterchangeable(t *testing.T) {
+	config.Set(config.Default())
+
+	clk := clock.NewFake(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC))
+
+	loginToken, err := CreateLoginToken(clk, "2115061001")
+
+	if err != nil {
+		t.Fatalf("create login token: %v", err)
+	}
+
+	updateToken, err := CreateUpdateAbsentListToken(clk, 1, "2115061001")
+
+	if err != nil {
+		t.Fatalf("create update absent list token: %v", err)
+	}
+
+	if err := ValidateLoginToken(clk, updateToken); err == nil {
+		t.Error("update absent list token was accepted as a login token")
+	}
+
+	if err := ExtractJWTPayload(clk, loginToken, &UpdateAbsentListClaims{}); err == nil {
+		t.Error("login token was accepted as an update absent list token")
+	}
+}
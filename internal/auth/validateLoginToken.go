@@ -1,7 +1,34 @@
 package auth
 
-func ValidateLoginToken(loginToken string) error {
-	// claims := &jwt.Claims{}
+import (
+	"errors"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/logger"
 
-	return nil
+	"github.com/golang-jwt/jwt"
+)
+
+func ValidateLoginToken(clk clock.Clock, loginToken string) error {
+	_, err := ParseLoginToken(clk, loginToken)
+
+	return err
+}
+
+// ParseLoginToken checks the signature, audience and expiry of a login token
+// against clk. An update absent list token is refused.
+func ParseLoginToken(clk clock.Clock, loginToken string) (*jwt.Token, error) {
+	claims := &jwtCustomClaims{}
+
+	token, err := parser.ParseWithClaims(loginToken, claims, signingKey)
+
+	if err == nil && token.Valid {
+		err = validateClaims(claims.StandardClaims, audienceLogin, clk.Now())
+	}
+
+	if err != nil || !token.Valid {
+		logger.Default().Debug("Invalid login token used")
+		return nil, errors.New("invalid login token")
+	}
+
+	return token, nil
 }
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+type API struct {
+	// LegacySunset is the date the unversioned routes will be removed, sent in
+	// the Sunset header of every legacy response. Format: YYYY-MM-DD.
+	LegacySunset string `yaml:"legacy_sunset" env:"LEGACY_API_SUNSET"`
+}
+
+func defaultAPI() API {
+	return API{LegacySunset: "2027-06-30"}
+}
+
+// LegacySunsetDate is LegacySunset parsed, Validate makes sure it parses.
+func (a API) LegacySunsetDate() time.Time {
+	sunset, _ := time.Parse(time.DateOnly, a.LegacySunset)
+
+	return sunset
+}
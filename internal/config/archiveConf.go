@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type Archive struct {
+	// Retention is how long an archived absent form is kept before forms purge
+	// deletes it for good.
+	Retention time.Duration `yaml:"retention" env:"ARCHIVE_RETENTION"`
+}
+
+func defaultArchive() Archive {
+	return Archive{Retention: 30 * 24 * time.Hour}
+}
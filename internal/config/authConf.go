@@ -1,60 +1,29 @@
 package config
 
-import (
-	"himatro-api/internal/util"
-	"log"
-	"os"
-	"strconv"
+// defaultSecretKey is only meant for local development, production refuses it.
+const defaultSecretKey = "INSECURE-DEVELOPMENT-ONLY-KEY-00"
 
-	_ "github.com/joho/godotenv/autoload"
-)
+type Auth struct {
+	// SecretKey encrypts the stored passwords, it must be 16, 24 or 32 bytes.
+	SecretKey     string `yaml:"secret_key" env:"SECRET_KEY" secret:"true"`
+	JWTSigningKey string `yaml:"jwt_signing_key" env:"JWT_SECRET_SIGNING_KEY" secret:"true"`
 
-func LoginTokenExpSec() int {
-	tokenExpSec, err := strconv.Atoi(os.Getenv("LOGIN_TOKEN_EXP_SEC"))
-
-	if err != nil {
-		util.LogErr("WARN", "LOGIN_TOKEN_EXP_SEC is not found in the env", err.Error())
-		log.Println("Unable to locate token exp sec from .env file, using default value...")
-		return 604800 // 7 days
-	}
-
-	return tokenExpSec
+	LoginTokenExpSec            int    `yaml:"login_token_exp_sec" env:"LOGIN_TOKEN_EXP_SEC"`
+	UpdateAbsentListTokenExpSec int    `yaml:"update_absent_list_token_exp_sec" env:"UPDATE_ABSENT_LIST_TOKEN_EXP_SEC"`
+	UpdateAbsentListCookieName  string `yaml:"update_absent_list_cookie_name" env:"UPDATE_ABSENT_LIST_COOKIE_NAME"`
 }
 
-func UpdateAbsentListTokenExpSec() int {
-	exp, err := strconv.Atoi(os.Getenv("UPDATE_ABSENT_LIST_TOKEN_EXP_SEC"))
-
-	if err != nil {
-		util.LogErr("WARN", "UPDATE_ABSENT_LIST_TOKEN_EXP_SEC is not found in the env", err.Error())
-		log.Println("unable to locate absent list token expired sec, using default value...")
-		exp = 3600 // 1 hour expiry
+func defaultAuth() Auth {
+	return Auth{
+		SecretKey:                   defaultSecretKey,
+		JWTSigningKey:               defaultSecretKey,
+		LoginTokenExpSec:            604800, // 7 days
+		UpdateAbsentListTokenExpSec: 3600,   // 1 hour
+		UpdateAbsentListCookieName:  "UPDATE_ABSENT_LIST_COOKIE",
 	}
-
-	return exp
 }
 
-func UpdateAbsentListCookieName() string {
-	name := os.Getenv("UPDATE_ABSENT_LIST_COOKIE_NAME")
-
-	if name == "" {
-		util.LogErr("WARN", "UPDATE_ABSENT_LIST_COOKIE_NAME is not found in the env", "")
-		log.Print("unable to locate update absent list cookie name, using default value...")
-
-		return "UPDATE_ABSENT_LIST_COOKIE"
-	}
-
-	return name
-}
-
-func JWTSigningKey() string {
-	key := os.Getenv("JWT_SECRET_SIGNING_KEY")
-
-	if key == "" {
-		util.LogErr("WARN", "JWT_SECRET_SIGNING_KEY is not found in the env", "")
-		log.Println("WARNING! No JWT_SECRET_SIGNING_KEY provided. Using the default one is not save at all.")
-
-		return defaultSecretKey
-	}
-
-	return key
+// UsesDefaultSecrets reports whether a secret is still the development default.
+func (a Auth) UsesDefaultSecrets() bool {
+	return a.SecretKey == defaultSecretKey || a.JWTSigningKey == defaultSecretKey
 }
@@ -0,0 +1,112 @@
+// Package config loads every setting of the API once at startup: the defaults,
+// then an optional YAML file, then the environment (including .env). The result
+// is validated before any command runs.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	_ "github.com/joho/godotenv/autoload"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	// Env is the deployment, e.g. local, staging or production, in any case.
+	// Only the development values may run with the default secrets, see
+	// IsDevelopment.
+	Env string `yaml:"env" env:"ENV"`
+
+	Server      Server      `yaml:"server"`
+	TLS         TLS         `yaml:"tls"`
+	Database    Database    `yaml:"database"`
+	Auth        Auth        `yaml:"auth"`
+	Log         Log         `yaml:"log"`
+	Metrics     Metrics     `yaml:"metrics"`
+	Tracing     Tracing     `yaml:"tracing"`
+	API         API         `yaml:"api"`
+	Idempotency Idempotency `yaml:"idempotency"`
+	Archive     Archive     `yaml:"archive"`
+	Seeder      Seeder      `yaml:"seeder"`
+}
+
+var current atomic.Pointer[Config]
+
+// Default returns the config used when neither the file nor the environment
+// sets a value.
+func Default() *Config {
+	return &Config{
+		Env:         "",
+		Server:      defaultServer(),
+		TLS:         defaultTLS(),
+		Database:    defaultDatabase(),
+		Auth:        defaultAuth(),
+		Log:         defaultLog(),
+		Tracing:     defaultTracing(),
+		API:         defaultAPI(),
+		Idempotency: defaultIdempotency(),
+		Archive:     defaultArchive(),
+		Seeder:      defaultSeeder(),
+	}
+}
+
+// Load reads the YAML file at path, when path is not empty, over the defaults,
+// then applies the environment. It doesn't validate the result.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("config file %s is invalid: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Set makes cfg the config returned by Get.
+func Set(cfg *Config) {
+	current.Store(cfg)
+}
+
+// Get returns the config loaded at startup. Commands load it before they run,
+// so it is only missing when a program forgets to call Set.
+func Get() *Config {
+	cfg := current.Load()
+
+	if cfg == nil {
+		panic("config.Get called before the config was loaded")
+	}
+
+	return cfg
+}
+
+// IsDevelopment reports whether Env is explicitly local, dev, development or
+// test. An unset Env is not, so a deployment that forgets ENV still needs its
+// own secrets.
+func (c *Config) IsDevelopment() bool {
+	switch strings.ToLower(c.Env) {
+	case "local", "dev", "development", "test":
+		return true
+	default:
+		return false
+	}
+}
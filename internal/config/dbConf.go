@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+type Database struct {
+	// Driver is the database the API runs on: postgres or sqlite. SQLite needs
+	// no server and is meant for local development and tests.
+	Driver string `yaml:"driver" env:"DB_DRIVER"`
+
+	// SQLitePath is the SQLite database file, :memory: for a throwaway database.
+	SQLitePath string `yaml:"sqlite_path" env:"SQLITE_PATH"`
+
+	// URL (postgres://...) is used instead of the Host, Port, ... fields when set.
+	URL      string `yaml:"url" env:"DATABASE_URL"`
+	Host     string `yaml:"host" env:"PG_HOST"`
+	Port     string `yaml:"port" env:"PG_PORT"`
+	User     string `yaml:"user" env:"PG_USER"`
+	Password string `yaml:"password" env:"PG_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"PG_DATABASE"`
+
+	// SSLMode is the libpq sslmode: disable, allow, prefer, require, verify-ca or verify-full.
+	SSLMode          string        `yaml:"ssl_mode" env:"DB_SSL_MODE"`
+	SSLRootCert      string        `yaml:"ssl_root_cert" env:"DB_SSL_ROOT_CERT"`
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+
+	// The connection pool of Postgres, SQLite always uses a single connection.
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+
+	// The startup connection is retried ConnectRetries times, with the wait
+	// doubling from ConnectBackoff up to ConnectMaxBackoff.
+	ConnectRetries    int           `yaml:"connect_retries" env:"DB_CONNECT_RETRIES"`
+	ConnectBackoff    time.Duration `yaml:"connect_backoff" env:"DB_CONNECT_BACKOFF"`
+	ConnectMaxBackoff time.Duration `yaml:"connect_max_backoff" env:"DB_CONNECT_MAX_BACKOFF"`
+}
+
+func defaultDatabase() Database {
+	return Database{
+		Driver:            DriverPostgres,
+		SQLitePath:        "himatro.db",
+		SSLMode:           "disable",
+		MaxOpenConns:      25,
+		MaxIdleConns:      5,
+		ConnMaxLifetime:   30 * time.Minute,
+		ConnectRetries:    10,
+		ConnectBackoff:    time.Second,
+		ConnectMaxBackoff: 30 * time.Second,
+	}
+}
+
+// ConnString is the Postgres DSN. URL is used as is when set, otherwise the DSN
+// is built from the other fields. SSLMode, SSLRootCert and StatementTimeout are
+// added to both, unless URL already has them.
+func (d Database) ConnString() string {
+	params := map[string]string{
+		"sslmode": d.SSLMode,
+	}
+
+	if d.SSLRootCert != "" {
+		params["sslrootcert"] = d.SSLRootCert
+	}
+
+	if d.StatementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(d.StatementTimeout.Milliseconds(), 10)
+	}
+
+	if d.URL != "" {
+		return withURLParams(d.URL, params)
+	}
+
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s", d.Host, d.User, d.Password, d.Name, d.Port)
+
+	for _, key := range sortedKeys(params) {
+		dsn += fmt.Sprintf(" %s=%s", key, params[key])
+	}
+
+	return dsn
+}
+
+// SQLiteDSN is SQLitePath opened with foreign keys enforced.
+func (d Database) SQLiteDSN() string {
+	separator := "?"
+
+	if strings.Contains(d.SQLitePath, "?") {
+		separator = "&"
+	}
+
+	return d.SQLitePath + separator + "_foreign_keys=on&_busy_timeout=5000"
+}
+
+// withURLParams adds params to databaseURL, Validate makes sure it parses.
+func withURLParams(databaseURL string, params map[string]string) string {
+	parsed, err := url.Parse(databaseURL)
+
+	if err != nil {
+		return databaseURL
+	}
+
+	query := parsed.Query()
+
+	for key, value := range params {
+		if !query.Has(key) {
+			query.Set(key, value)
+		}
+	}
+
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
+
+func sortedKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+
+	for key := range params {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
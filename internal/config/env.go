@@ -0,0 +1,175 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Setting is one effective value of the config, as shown by config print.
+type Setting struct {
+	Key   string // path in the YAML file, e.g. database.max_open_conns
+	Env   string // environment variable, e.g. DB_MAX_OPEN_CONNS
+	Value string
+}
+
+// field is a leaf of Config with its YAML path and env tag.
+type field struct {
+	key    string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+func fields(cfg *Config) []field {
+	found := []field{}
+	walk(reflect.ValueOf(cfg).Elem(), "", &found)
+
+	return found
+}
+
+func walk(v reflect.Value, prefix string, found *[]field) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if sf.Type.Kind() == reflect.Struct {
+			walk(v.Field(i), key, found)
+			continue
+		}
+
+		*found = append(*found, field{
+			key:    key,
+			env:    sf.Tag.Get("env"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+}
+
+// applyEnv sets every field whose environment variable is set and not empty.
+func applyEnv(cfg *Config) error {
+	errs := []error{}
+
+	for _, f := range fields(cfg) {
+		raw, ok := os.LookupEnv(f.env)
+
+		if f.env == "" || !ok || raw == "" {
+			continue
+		}
+
+		if err := setValue(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 30s or 24h, got %q", raw)
+		}
+
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", raw)
+		}
+
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", raw)
+		}
+
+		v.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", raw)
+		}
+
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.Set(reflect.ValueOf(strings.Split(raw, ",")))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// Settings lists every effective value, with secrets and the password of
+// DATABASE_URL redacted.
+func (c *Config) Settings() []Setting {
+	settings := []Setting{}
+
+	for _, f := range fields(c) {
+		value := format(f.value)
+
+		if f.secret && value != "" {
+			value = redacted
+		}
+
+		if f.env == "DATABASE_URL" && value != "" {
+			value = redactURL(value)
+		}
+
+		settings = append(settings, Setting{Key: f.key, Env: f.env, Value: value})
+	}
+
+	return settings
+}
+
+func format(v reflect.Value) string {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	if v.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+
+	if err != nil {
+		return redacted
+	}
+
+	return parsed.Redacted()
+}
@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+type Idempotency struct {
+	// TTL is how long a response is kept for replays of the same Idempotency-Key.
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+
+	// CleanupInterval is how often the server deletes the expired keys.
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"IDEMPOTENCY_CLEANUP_INTERVAL"`
+}
+
+func defaultIdempotency() Idempotency {
+	return Idempotency{
+		TTL:             24 * time.Hour,
+		CleanupInterval: time.Hour,
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"himatro-api/internal/logger"
+	"time"
+)
+
+type Log struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	Output string `yaml:"output" env:"LOG_OUTPUT"`
+
+	// FileName is the request log, ErrFileName the application log when Output is file or both.
+	FileName    string `yaml:"file_name" env:"LOG_FILE_NAME"`
+	ErrFileName string `yaml:"err_file_name" env:"ERR_LOG_FILE_NAME"`
+
+	// SkippedRoutes are left out of the request log.
+	SkippedRoutes []string `yaml:"skipped_routes" env:"LOG_SKIPPED_ROUTES"`
+
+	MaxSizeMB   int           `yaml:"max_size_mb" env:"LOG_MAX_SIZE_MB"`
+	MaxAgeDays  int           `yaml:"max_age_days" env:"LOG_MAX_AGE_DAYS"`
+	MaxBackups  int           `yaml:"max_backups" env:"LOG_MAX_BACKUPS"`
+	Compress    bool          `yaml:"compress" env:"LOG_COMPRESS"`
+	RotateEvery time.Duration `yaml:"rotate_every" env:"LOG_ROTATE_EVERY"`
+}
+
+func defaultLog() Log {
+	return Log{
+		Level:         "info",
+		Format:        "logfmt",
+		Output:        "stdout",
+		SkippedRoutes: []string{"/login", "/v1/login", "/healthz", "/readyz"},
+		MaxSizeMB:     100,
+		MaxAgeDays:    30,
+		MaxBackups:    10,
+		Compress:      true,
+	}
+}
+
+// RotateOptions applies to both the request log and the application log file.
+func (l Log) RotateOptions() logger.RotateOptions {
+	return logger.RotateOptions{
+		MaxSizeMB:   l.MaxSizeMB,
+		MaxAgeDays:  l.MaxAgeDays,
+		MaxBackups:  l.MaxBackups,
+		Compress:    l.Compress,
+		RotateEvery: l.RotateEvery,
+	}
+}
@@ -0,0 +1,9 @@
+package config
+
+type Metrics struct {
+	// APIKey is the bearer token required to read /metrics on the main server.
+	APIKey string `yaml:"api_key" env:"METRICS_API_KEY" secret:"true"`
+
+	// Addr is an optional separate listen address for /metrics, e.g. 127.0.0.1:9090.
+	Addr string `yaml:"addr" env:"METRICS_ADDR"`
+}
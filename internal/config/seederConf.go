@@ -0,0 +1,27 @@
+package config
+
+// Seeder holds the CSV files read by the seeder command and the header each of
+// them must start with.
+type Seeder struct {
+	AnggotaBiasaPath string `yaml:"anggota_biasa_path" env:"ANGGOTA_BIASA_SEEDER_DATA_PATH"`
+	DepartemenPath   string `yaml:"departemen_path" env:"DEPARTEMEN_SEEDER_DATA_PATH"`
+	PengurusPath     string `yaml:"pengurus_path" env:"PENGURUS_SEEDER_DATA_PATH"`
+	JabatanPath      string `yaml:"jabatan_path" env:"JABATAN_SEEDER_DATA_PATH"`
+	SuperAdminPath   string `yaml:"super_admin_path" env:"SUPER_ADMIN_SEEDER_DATA_PATH"`
+
+	AnggotaBiasaHeader []string `yaml:"anggota_biasa_header" env:"ANGGOTA_BIASA_CSV_HEADER_CONFIG"`
+	DepartemenHeader   []string `yaml:"departemen_header" env:"DEPARTEMEN_CSV_HEADER_CONFIG"`
+	PengurusHeader     []string `yaml:"pengurus_header" env:"PENGURUS_CSV_HEADER_CONFIG"`
+	JabatanHeader      []string `yaml:"jabatan_header" env:"JABATAN_CSV_HEADER_CONFIG"`
+	SuperAdminHeader   []string `yaml:"super_admin_header" env:"SUPER_ADMIN_CSV_HEADER_CONFIG"`
+}
+
+func defaultSeeder() Seeder {
+	return Seeder{
+		AnggotaBiasaHeader: []string{"npm", "nama"},
+		DepartemenHeader:   []string{"ID", "nama"},
+		PengurusHeader:     []string{"npm", "departemenID", "jabatanID"},
+		JabatanHeader:      []string{"ID", "privilegeLevel", "nama"},
+		SuperAdminHeader:   []string{"npm", "password"},
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type Server struct {
+	// Addr is the listen address, e.g. :8080.
+	Addr string `yaml:"addr" env:"SERVER_PORT"`
+
+	// Socket is a Unix socket path to listen on instead of Addr, e.g. for the
+	// Nginx upstream. SocketMode is its file mode in octal.
+	Socket     string `yaml:"socket" env:"SERVER_SOCKET"`
+	SocketMode string `yaml:"socket_mode" env:"SERVER_SOCKET_MODE"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+
+	// ShutdownTimeout is how long requests in flight may take to finish after
+	// SIGTERM or SIGINT before their connections are closed.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+
+	MaxHeaderBytes int `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+
+	// BodyLimit is the largest request body, e.g. 2M. Larger ones get 413.
+	BodyLimit string `yaml:"body_limit" env:"SERVER_BODY_LIMIT"`
+
+	// StorageDir is the directory the server writes its files to, e.g. the log files.
+	StorageDir string `yaml:"storage_dir" env:"STORAGE_DIR"`
+
+	// TimeZone is the IANA zone form schedules are entered in.
+	TimeZone string `yaml:"time_zone" env:"TZ"`
+}
+
+func defaultServer() Server {
+	return Server{
+		Addr:              ":8080",
+		SocketMode:        "0660",
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		ShutdownTimeout:   20 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		BodyLimit:         "2M",
+		StorageDir:        ".",
+		TimeZone:          "Asia/Jakarta",
+	}
+}
+
+// SocketFileMode is SocketMode parsed, Validate makes sure it parses.
+func (s Server) SocketFileMode() os.FileMode {
+	mode, _ := strconv.ParseUint(s.SocketMode, 8, 32)
+
+	return os.FileMode(mode)
+}
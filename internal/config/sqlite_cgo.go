@@ -0,0 +1,7 @@
+//go:build cgo
+
+package config
+
+// sqliteAvailable reports whether this binary can open SQLite databases. The
+// SQLite driver is built with cgo, without it every connection fails.
+const sqliteAvailable = true
@@ -0,0 +1,40 @@
+package config
+
+const (
+	TLSOff   = "off"
+	TLSFiles = "files"
+	TLSACME  = "acme"
+)
+
+// TLS lets the server terminate HTTPS itself instead of Nginx.
+type TLS struct {
+	// Mode is off, files (CertFile and KeyFile, reloaded when they change) or
+	// acme (certificates from Let's Encrypt for ACMEDomains).
+	Mode string `yaml:"mode" env:"TLS_MODE"`
+
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE"`
+
+	ACMEDomains  []string `yaml:"acme_domains" env:"TLS_ACME_DOMAINS"`
+	ACMEEmail    string   `yaml:"acme_email" env:"TLS_ACME_EMAIL"`
+	ACMECacheDir string   `yaml:"acme_cache_dir" env:"TLS_ACME_CACHE_DIR"`
+
+	// ACMEDirectoryURL is the ACME server, empty for Let's Encrypt production.
+	ACMEDirectoryURL string `yaml:"acme_directory_url" env:"TLS_ACME_DIRECTORY_URL"`
+
+	// RedirectAddr is the plain HTTP listener that redirects to HTTPS and
+	// answers the ACME challenges. Empty disables it.
+	RedirectAddr string `yaml:"redirect_addr" env:"TLS_REDIRECT_ADDR"`
+}
+
+func defaultTLS() TLS {
+	return TLS{
+		Mode:         TLSOff,
+		ACMECacheDir: "acme-cache",
+		RedirectAddr: ":80",
+	}
+}
+
+func (t TLS) Enabled() bool {
+	return t.Mode != TLSOff
+}
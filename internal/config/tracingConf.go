@@ -0,0 +1,15 @@
+package config
+
+type Tracing struct {
+	Enabled bool `yaml:"enabled" env:"TRACING_ENABLED"`
+
+	// Endpoint is the host:port of the OTLP/HTTP collector. When empty the
+	// exporter falls back to OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318.
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+func defaultTracing() Tracing {
+	return Tracing{SampleRatio: 1}
+}
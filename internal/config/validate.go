@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"slices"
+	"time"
+
+	"github.com/labstack/gommon/bytes"
+)
+
+// Validate returns every invalid setting at once.
+func (c *Config) Validate() error {
+	errs := []error{}
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	oneOf := func(name string, value string, allowed ...string) {
+		check(slices.Contains(allowed, value), "%s must be one of %v, got %q", name, allowed, value)
+	}
+
+	notNegative := func(name string, value int64) {
+		check(value >= 0, "%s must not be negative", name)
+	}
+
+	server := c.Server
+	check(server.Addr != "" || server.Socket != "", "SERVER_PORT or SERVER_SOCKET must be set")
+
+	_, err := strconv.ParseUint(server.SocketMode, 8, 32)
+	check(err == nil, "SERVER_SOCKET_MODE must be an octal file mode such as 0660, got %q", server.SocketMode)
+
+	notNegative("SERVER_READ_TIMEOUT", int64(server.ReadTimeout))
+	notNegative("SERVER_READ_HEADER_TIMEOUT", int64(server.ReadHeaderTimeout))
+	notNegative("SERVER_WRITE_TIMEOUT", int64(server.WriteTimeout))
+	notNegative("SERVER_IDLE_TIMEOUT", int64(server.IdleTimeout))
+	notNegative("SERVER_SHUTDOWN_TIMEOUT", int64(server.ShutdownTimeout))
+	check(server.MaxHeaderBytes > 0, "SERVER_MAX_HEADER_BYTES must be positive")
+
+	limit, err := bytes.Parse(server.BodyLimit)
+	check(err == nil && limit > 0, "SERVER_BODY_LIMIT must be a size such as 2M, got %q", server.BodyLimit)
+
+	tls := c.TLS
+	oneOf("TLS_MODE", tls.Mode, TLSOff, TLSFiles, TLSACME)
+
+	if tls.Mode == TLSFiles {
+		check(tls.CertFile != "" && tls.KeyFile != "", "TLS_CERT_FILE and TLS_KEY_FILE must be set when TLS_MODE is files")
+	}
+
+	if tls.Mode == TLSACME {
+		check(len(tls.ACMEDomains) > 0, "TLS_ACME_DOMAINS must be set when TLS_MODE is acme")
+		check(tls.ACMECacheDir != "", "TLS_ACME_CACHE_DIR must be set when TLS_MODE is acme")
+	}
+
+	_, err = time.LoadLocation(c.Server.TimeZone)
+	check(err == nil, "TZ %q is not a known time zone", c.Server.TimeZone)
+
+	db := c.Database
+	oneOf("DB_DRIVER", db.Driver, DriverPostgres, DriverSQLite)
+	check(db.Driver != DriverSQLite || sqliteAvailable, "DB_DRIVER sqlite needs a binary built with cgo, this one was built with CGO_ENABLED=0")
+	oneOf("DB_SSL_MODE", db.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+
+	if db.URL != "" {
+		parsed, err := url.Parse(db.URL)
+		check(err == nil && (parsed.Scheme == "postgres" || parsed.Scheme == "postgresql"), "DATABASE_URL must be a postgres:// URL")
+	}
+
+	notNegative("DB_STATEMENT_TIMEOUT", int64(db.StatementTimeout))
+	notNegative("DB_MAX_OPEN_CONNS", int64(db.MaxOpenConns))
+	notNegative("DB_MAX_IDLE_CONNS", int64(db.MaxIdleConns))
+	notNegative("DB_CONN_MAX_LIFETIME", int64(db.ConnMaxLifetime))
+	notNegative("DB_CONNECT_RETRIES", int64(db.ConnectRetries))
+	notNegative("DB_CONNECT_BACKOFF", int64(db.ConnectBackoff))
+	notNegative("DB_CONNECT_MAX_BACKOFF", int64(db.ConnectMaxBackoff))
+
+	auth := c.Auth
+	check(slices.Contains([]int{16, 24, 32}, len(auth.SecretKey)), "SECRET_KEY must be 16, 24 or 32 bytes long")
+	check(auth.JWTSigningKey != "", "JWT_SECRET_SIGNING_KEY must not be empty")
+	check(auth.LoginTokenExpSec > 0, "LOGIN_TOKEN_EXP_SEC must be positive")
+	check(auth.UpdateAbsentListTokenExpSec > 0, "UPDATE_ABSENT_LIST_TOKEN_EXP_SEC must be positive")
+	check(auth.UpdateAbsentListCookieName != "", "UPDATE_ABSENT_LIST_COOKIE_NAME must not be empty")
+
+	if !c.IsDevelopment() {
+		check(auth.SecretKey != defaultSecretKey, "SECRET_KEY must be set unless ENV is local, dev, development or test, the default one is public")
+		check(auth.JWTSigningKey != defaultSecretKey, "JWT_SECRET_SIGNING_KEY must be set unless ENV is local, dev, development or test, the default one is public")
+	}
+
+	// the logger reads these in any case
+	log := c.Log
+	oneOf("LOG_LEVEL", strings.ToLower(log.Level), "debug", "info", "warn", "warning", "error")
+	oneOf("LOG_FORMAT", strings.ToLower(log.Format), "logfmt", "text", "json")
+	oneOf("LOG_OUTPUT", strings.ToLower(log.Output), "stdout", "file", "both")
+	check(strings.EqualFold(log.Output, "stdout") || log.ErrFileName != "", "ERR_LOG_FILE_NAME must be set when LOG_OUTPUT is %s", log.Output)
+	notNegative("LOG_MAX_SIZE_MB", int64(log.MaxSizeMB))
+	notNegative("LOG_MAX_AGE_DAYS", int64(log.MaxAgeDays))
+	notNegative("LOG_MAX_BACKUPS", int64(log.MaxBackups))
+	notNegative("LOG_ROTATE_EVERY", int64(log.RotateEvery))
+
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	_, err = time.Parse(time.DateOnly, c.API.LegacySunset)
+	check(err == nil, "LEGACY_API_SUNSET must be a date such as 2027-06-30, got %q", c.API.LegacySunset)
+
+	check(c.Idempotency.TTL > 0, "IDEMPOTENCY_TTL must be positive")
+	check(c.Idempotency.CleanupInterval > 0, "IDEMPOTENCY_CLEANUP_INTERVAL must be positive")
+	check(c.Archive.Retention > 0, "ARCHIVE_RETENTION must be positive")
+
+	return errors.Join(errs...)
+}
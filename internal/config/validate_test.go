@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDefaultSecrets(t *testing.T) {
+	tests := []struct {
+		env   string
+		valid bool
+	}{
+		{"local", true},
+		{"Local", true},
+		{"dev", true},
+		{"DEVELOPMENT", true},
+		{"test", true},
+		{"", false},
+		{"staging", false},
+		{"production", false},
+		{"PROD", false},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.Env = tt.env
+
+		err := cfg.Validate()
+
+		if tt.valid && err != nil {
+			t.Errorf("ENV %q with the default secrets: got %v, want valid", tt.env, err)
+		}
+
+		if !tt.valid && (err == nil || !strings.Contains(err.Error(), "JWT_SECRET_SIGNING_KEY must be set")) {
+			t.Errorf("ENV %q with the default secrets: got %v, want the secrets refused", tt.env, err)
+		}
+	}
+}
+
+func TestValidateOwnSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Env = "production"
+	cfg.Auth.SecretKey = "0123456789abcdef0123456789abcdef"
+	cfg.Auth.JWTSigningKey = "another-secret"
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("production with its own secrets: got %v, want valid", err)
+	}
+}
+
+func TestValidateLogCase(t *testing.T) {
+	cfg := Default()
+	cfg.Env = "local"
+	cfg.Log.Level = "DEBUG"
+	cfg.Log.Format = "JSON"
+	cfg.Log.Output = "Stdout"
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("upper case log settings: got %v, want valid", err)
+	}
+
+	cfg.Log.Level = "verbose"
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "LOG_LEVEL") {
+		t.Errorf("unknown log level: got %v, want LOG_LEVEL refused", err)
+	}
+}
@@ -0,0 +1,47 @@
+package console
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the effective configuration",
+	Long:  "Print every setting after the YAML file and the environment are applied, with secrets redacted. Invalid settings are listed after it.",
+	Args:  cobra.NoArgs,
+	// Replaces the root setup, an invalid config must still be printable.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run:              configPrint,
+}
+
+func init() {
+	configCmd.AddCommand(configPrintCmd)
+	RootCmd.AddCommand(configCmd)
+}
+
+func configPrint(cmd *cobra.Command, args []string) {
+	cfg := loadConfig(cmd)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tENV\tVALUE")
+
+	for _, s := range cfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Env, s.Value)
+	}
+
+	w.Flush()
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Config is invalid:\n%s", err)
+	}
+}
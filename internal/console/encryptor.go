@@ -4,7 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"himatro-api/internal/auth"
-	"himatro-api/internal/util"
+	"himatro-api/internal/logger"
 	"log"
 	"os"
 
@@ -31,7 +31,7 @@ func encryptor(cmd *cobra.Command, args []string) {
 	text, err := reader.ReadString(byte('\n'))
 
 	if err != nil {
-		util.LogErr("ERROR", "command encryptor is fail", err.Error())
+		logger.Default().Error("command encryptor is fail", logger.ErrorKey, err)
 		fmt.Println("command failed")
 		log.Panic(err.Error())
 	}
@@ -39,7 +39,7 @@ func encryptor(cmd *cobra.Command, args []string) {
 	encrypted, err := auth.Encrypt(text)
 
 	if err != nil {
-		util.LogErr("ERROR", "command encryptor is fail", err.Error())
+		logger.Default().Error("command encryptor is fail", logger.ErrorKey, err)
 		fmt.Println("command failed")
 		log.Panic(err.Error())
 	}
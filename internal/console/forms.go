@@ -0,0 +1,56 @@
+package console
+
+import (
+	"context"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/config"
+	"himatro-api/internal/controller"
+	"himatro-api/internal/db"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/repository"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+var formsCmd = &cobra.Command{
+	Use:   "forms",
+	Short: "Manage absent forms",
+	Long:  "Use this command to maintain the absent forms, e.g. to purge the archived ones",
+}
+
+var formsPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete archived absent forms",
+	Long:  "Permanently delete the absent forms archived for longer than ARCHIVE_RETENTION, or --older-than when set, together with their absent lists.",
+	Args:  cobra.NoArgs,
+	Run:   formsPurge,
+}
+
+func init() {
+	formsPurgeCmd.Flags().Duration("older-than", 0, "purge forms archived for longer than this, instead of ARCHIVE_RETENTION")
+
+	formsCmd.AddCommand(formsPurgeCmd)
+	RootCmd.AddCommand(formsCmd)
+}
+
+func formsPurge(cmd *cobra.Command, args []string) {
+	retention, _ := cmd.Flags().GetDuration("older-than")
+
+	if retention <= 0 {
+		retention = config.Get().Archive.Retention
+	}
+
+	db.Connect()
+
+	absent := controller.NewAbsentController(repository.NewGormStore(db.DB), clock.New(), logger.Default())
+
+	purged, err := absent.PurgeArchivedForms(context.Background(), retention)
+
+	if err != nil {
+		logger.Default().Error("command forms purge is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	log.Printf("Purged %d absent forms archived for longer than %s", purged, retention)
+}
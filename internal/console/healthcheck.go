@@ -0,0 +1,94 @@
+package console
+
+import (
+	"context"
+	"crypto/tls"
+	"himatro-api/internal/config"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var healthcheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Check that the running server is ready",
+	Long:  "Request /readyz from the server on SERVER_SOCKET or SERVER_PORT, over HTTPS when TLS_MODE is set, and fail unless it answers 200 OK. The Docker image runs it as its HEALTHCHECK.",
+	Args:  cobra.NoArgs,
+	Run:   healthcheck,
+}
+
+func init() {
+	healthcheckCmd.Flags().Duration("timeout", 5*time.Second, "how long to wait for the answer")
+
+	RootCmd.AddCommand(healthcheckCmd)
+}
+
+func healthcheck(cmd *cobra.Command, args []string) {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	client, url, err := readinessProbe(config.Get().Server, config.Get().TLS)
+
+	if err != nil {
+		log.Fatal("Failed to build the readiness probe: ", err)
+	}
+
+	client.Timeout = timeout
+
+	res, err := client.Get(url)
+
+	if err != nil {
+		log.Fatal("Server is not reachable: ", err)
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		log.Fatalf("Server is not ready: %s", res.Status)
+	}
+}
+
+// readinessProbe returns the client and URL that reach /readyz of the server
+// started with this config: over its Unix socket when one is set, otherwise on
+// the loopback address of its port.
+func readinessProbe(cfg config.Server, tlsCfg config.TLS) (*http.Client, string, error) {
+	transport := &http.Transport{}
+	scheme := "http"
+	host := "localhost"
+
+	if tlsCfg.Enabled() {
+		scheme = "https"
+
+		// The certificate is for the public domains, not for localhost. ACME only
+		// answers the handshake for one of its domains.
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+		if tlsCfg.Mode == config.TLSACME && len(tlsCfg.ACMEDomains) > 0 {
+			transport.TLSClientConfig.ServerName = tlsCfg.ACMEDomains[0]
+		}
+	}
+
+	if cfg.Socket != "" {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+
+			return dialer.DialContext(ctx, "unix", cfg.Socket)
+		}
+	} else {
+		addrHost, port, err := net.SplitHostPort(cfg.Addr)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		if ip := net.ParseIP(addrHost); addrHost != "" && (ip == nil || !ip.IsUnspecified()) {
+			host = addrHost
+		}
+
+		host = net.JoinHostPort(host, port)
+	}
+
+	return &http.Client{Transport: transport}, scheme + "://" + host + "/readyz", nil
+}
@@ -0,0 +1,100 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/controller"
+	"himatro-api/internal/db"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/repository"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var memberCmd = &cobra.Command{
+	Use:   "member",
+	Short: "Manage personal data of a member",
+	Long:  "Use this command to export or erase every data linked to a member NPM",
+}
+
+var memberExportCmd = &cobra.Command{
+	Use:   "export [NPM]",
+	Short: "Export member data",
+	Long:  "Export every data linked to the NPM as JSON. Use --out with a .zip file name to get a ZIP bundle.",
+	Args:  cobra.ExactArgs(1),
+	Run:   memberExport,
+}
+
+var memberEraseCmd = &cobra.Command{
+	Use:   "erase [NPM]",
+	Short: "Erase member data",
+	Long:  "Anonymize the member while keeping the attendance counts of every absent form correct.",
+	Args:  cobra.ExactArgs(1),
+	Run:   memberErase,
+}
+
+func init() {
+	memberExportCmd.Flags().StringP("out", "o", "", "write the export to this file instead of stdout")
+
+	memberCmd.AddCommand(memberExportCmd)
+	memberCmd.AddCommand(memberEraseCmd)
+	RootCmd.AddCommand(memberCmd)
+}
+
+func memberExport(cmd *cobra.Command, args []string) {
+	db.Connect()
+
+	members := controller.NewMemberController(repository.NewGormStore(db.DB), clock.New(), logger.Default())
+
+	memberData, err := members.ExportMemberData(context.Background(), args[0])
+
+	if err != nil {
+		logger.Default().Error("command member export is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	out, _ := cmd.Flags().GetString("out")
+
+	var content []byte
+
+	if strings.HasSuffix(out, ".zip") {
+		content, err = members.ZipMemberData(context.Background(), memberData)
+	} else {
+		content, err = json.MarshalIndent(memberData, "", "  ")
+	}
+
+	if err != nil {
+		logger.Default().Error("command member export is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	if out == "" {
+		fmt.Println(string(content))
+		return
+	}
+
+	if err := os.WriteFile(out, content, 0600); err != nil {
+		logger.Default().Error("command member export is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	log.Printf("Member data for %s exported to %s", args[0], out)
+}
+
+func memberErase(cmd *cobra.Command, args []string) {
+	db.Connect()
+
+	members := controller.NewMemberController(repository.NewGormStore(db.DB), clock.New(), logger.Default())
+
+	if err := members.EraseMemberData(context.Background(), args[0]); err != nil {
+		logger.Default().Error("command member erase is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	log.Printf("Member data for %s erased", args[0])
+}
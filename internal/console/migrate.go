@@ -1,8 +1,17 @@
 package console
 
 import (
+	"context"
+	"fmt"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/config"
 	"himatro-api/internal/db"
-	"himatro-api/internal/models"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/migration"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 )
@@ -10,22 +19,143 @@ import (
 var migrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Migrate all database table",
-	Long:  "Use this command to initialize your database table for the first time",
-	Run:   migrate,
+	Long:  "Use this command to apply every pending migration. It is the same as migrate up.",
+	Run:   migrateUp,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply every pending migration",
+	Args:  cobra.NoArgs,
+	Run:   migrateUp,
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [N]",
+	Short: "Roll back the last N migrations",
+	Long:  "Roll back the last N applied migrations, newest first. N defaults to 1.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   migrateDown,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which migrations are applied",
+	Args:  cobra.NoArgs,
+	Run:   migrateStatus,
+}
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a new migration",
+	Long:  "Create empty up and down SQL files for a new migration in the directory of every database driver. Rebuild the binary to embed them.",
+	Args:  cobra.ExactArgs(1),
+	Run:   migrateCreate,
 }
 
 func init() {
+	migrateCreateCmd.Flags().String("dir", migration.Dir, "directory holding the migration directory of every driver")
+
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateCreateCmd)
 	RootCmd.AddCommand(migrateCmd)
 }
 
-func migrate(cmd *cobra.Command, args []string) {
+func newMigrator() *migration.Migrator {
+	migrations, err := migration.Embedded(config.Get().Database.Driver)
+
+	if err != nil {
+		logger.Default().Error("Embedded migrations are invalid", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
 	db.Connect()
 
-	db.DB.AutoMigrate(&models.AnggotaBiasa{})
-	db.DB.AutoMigrate(&models.Jabatan{})
-	db.DB.AutoMigrate(&models.Pengurus{})
-	db.DB.AutoMigrate(&models.FormAbsensi{})
-	db.DB.AutoMigrate(&models.AbsentList{})
-	db.DB.AutoMigrate(&models.Departemen{})
-	db.DB.AutoMigrate(&models.User{})
+	return migration.New(db.DB, migrations, clock.New())
+}
+
+func migrateUp(cmd *cobra.Command, args []string) {
+	applied, err := newMigrator().Up(context.Background())
+
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+
+	if err != nil {
+		logger.Default().Error("command migrate up is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	if len(applied) == 0 {
+		log.Print("Database is up to date")
+	}
+}
+
+func migrateDown(cmd *cobra.Command, args []string) {
+	n := 1
+
+	if len(args) == 1 {
+		var err error
+		n, err = strconv.Atoi(args[0])
+
+		if err != nil || n < 1 {
+			log.Fatalf("N must be a positive number, got %q", args[0])
+		}
+	}
+
+	rolledBack, err := newMigrator().Down(context.Background(), n)
+
+	for _, m := range rolledBack {
+		log.Printf("Rolled back migration %04d_%s", m.Version, m.Name)
+	}
+
+	if err != nil {
+		logger.Default().Error("command migrate down is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	if len(rolledBack) == 0 {
+		log.Print("No migration to roll back")
+	}
+}
+
+func migrateStatus(cmd *cobra.Command, args []string) {
+	status, err := newMigrator().Status(context.Background())
+
+	if err != nil {
+		logger.Default().Error("command migrate status is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+
+	for _, s := range status {
+		appliedAt := "pending"
+
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+
+	w.Flush()
+}
+
+func migrateCreate(cmd *cobra.Command, args []string) {
+	dir, _ := cmd.Flags().GetString("dir")
+
+	created, err := migration.Create(dir, args[0])
+
+	if err != nil {
+		logger.Default().Error("command migrate create is fail", logger.ErrorKey, err)
+		log.Fatal(err.Error())
+	}
+
+	for _, path := range created {
+		log.Printf("Created %s", path)
+	}
 }
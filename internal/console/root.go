@@ -1,7 +1,9 @@
 package console
 
 import (
-	"himatro-api/internal/util"
+	"himatro-api/internal/config"
+	"himatro-api/internal/logger"
+	"io"
 	"log"
 	"os"
 
@@ -9,14 +11,73 @@ import (
 )
 
 var RootCmd = &cobra.Command{
-	Use: "Himatro API",
+	Use:              "Himatro API",
+	PersistentPreRun: setup,
 }
 
+func init() {
+	RootCmd.PersistentFlags().String("config", os.Getenv("CONFIG_FILE"), "optional YAML config file, the environment overrides it")
+}
+
+var logCloser io.Closer
+
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
-		util.LogErr("ERROR", "command failed to execute", err.Error())
+	err := RootCmd.Execute()
+
+	if logCloser != nil {
+		logCloser.Close()
+	}
+
+	if err != nil {
+		logger.Default().Error("command failed to execute", logger.ErrorKey, err)
 		log.Panic(err.Error())
 
 		os.Exit(1)
 	}
 }
+
+// setup loads and validates the config, then starts the logger. Every command
+// runs it first, so a misconfigured server never starts.
+func setup(cmd *cobra.Command, args []string) {
+	cfg := loadConfig(cmd)
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Config is invalid:\n%s", err)
+	}
+
+	config.Set(cfg)
+	initLogger(cfg)
+
+	if cfg.Auth.UsesDefaultSecrets() {
+		logger.Default().Warn("SECRET_KEY or JWT_SECRET_SIGNING_KEY is not set, using the default one is not safe at all")
+	}
+}
+
+func loadConfig(cmd *cobra.Command) *config.Config {
+	path, _ := cmd.Flags().GetString("config")
+
+	cfg, err := config.Load(path)
+
+	if err != nil {
+		log.Fatalf("Failed to load config: %s", err)
+	}
+
+	return cfg
+}
+
+func initLogger(cfg *config.Config) {
+	l, closer, err := logger.New(logger.Options{
+		Format:   cfg.Log.Format,
+		Level:    cfg.Log.Level,
+		Output:   cfg.Log.Output,
+		FilePath: cfg.Log.ErrFileName,
+		Rotate:   cfg.Log.RotateOptions(),
+	})
+
+	if err != nil {
+		log.Fatal("Failed to initialize logger: ", err)
+	}
+
+	logCloser = closer
+	logger.SetDefault(l)
+}
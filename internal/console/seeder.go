@@ -3,13 +3,13 @@ package console
 import (
 	"encoding/csv"
 	"fmt"
+	"himatro-api/internal/config"
 	"himatro-api/internal/db"
+	"himatro-api/internal/logger"
 	"himatro-api/internal/models"
-	"himatro-api/internal/util"
 	"log"
 	"os"
 	"strconv"
-	"strings"
 
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/spf13/cobra"
@@ -36,10 +36,10 @@ func seeder(cmd *cobra.Command, args []string) {
 }
 
 func seedAnggotaBiasa() {
-	filepath := os.Getenv("ANGGOTA_BIASA_SEEDER_DATA_PATH")
+	filepath := config.Get().Seeder.AnggotaBiasaPath
 	data := readFromCSV(filepath)
 
-	validateCSVColumnHeader(data[0], "ANGGOTA_BIASA_CSV_HEADER_CONFIG")
+	validateCSVColumnHeader(data[0], "ANGGOTA_BIASA_CSV_HEADER_CONFIG", config.Get().Seeder.AnggotaBiasaHeader)
 
 	for i := 1; i < len(data); i++ {
 		anggotaBiasa := models.AnggotaBiasa{
@@ -52,23 +52,22 @@ func seedAnggotaBiasa() {
 		result := db.DB.Create(&anggotaBiasa)
 
 		if result.Error != nil {
-			util.LogErr("WARN", fmt.Sprintf("Failed to insert data for %q", anggotaBiasa), result.Error.Error())
-			log.Printf("Failed to insert data for %q because: %s", anggotaBiasa, result.Error)
+			logger.Default().Warn(fmt.Sprintf("Failed to insert data for %q", anggotaBiasa), logger.ErrorKey, result.Error)
 		}
 	}
 }
 
 func seedDepartemen() {
-	filepath := os.Getenv("DEPARTEMEN_SEEDER_DATA_PATH")
+	filepath := config.Get().Seeder.DepartemenPath
 	data := readFromCSV(filepath)
 
-	validateCSVColumnHeader(data[0], "DEPARTEMEN_CSV_HEADER_CONFIG")
+	validateCSVColumnHeader(data[0], "DEPARTEMEN_CSV_HEADER_CONFIG", config.Get().Seeder.DepartemenHeader)
 
 	for i := 1; i < len(data); i++ {
 		id, err := strconv.ParseInt(data[i][0], 10, 8)
 
 		if err != nil {
-			util.LogErr("ERROR", "Invalid value in departemenID from corespondense CSV file.", err.Error())
+			logger.Default().Error("Invalid value in departemenID from corespondense CSV file.", logger.ErrorKey, err)
 			panic("Invalid value in departemenID from corespondense CSV file.")
 		}
 
@@ -82,30 +81,29 @@ func seedDepartemen() {
 		result := db.DB.Create(&departemen)
 
 		if result.Error != nil {
-			util.LogErr("ERROR", fmt.Sprintf("Failed to insert data for %q", departemen), result.Error.Error())
-			log.Printf("Failed to insert data for %q because: %s", departemen, result.Error)
+			logger.Default().Error(fmt.Sprintf("Failed to insert data for %q", departemen), logger.ErrorKey, result.Error)
 		}
 	}
 }
 
 func seedPengurus() {
-	filepath := os.Getenv("PENGURUS_SEEDER_DATA_PATH")
+	filepath := config.Get().Seeder.PengurusPath
 	data := readFromCSV(filepath)
 
-	validateCSVColumnHeader(data[0], "PENGURUS_CSV_HEADER_CONFIG")
+	validateCSVColumnHeader(data[0], "PENGURUS_CSV_HEADER_CONFIG", config.Get().Seeder.PengurusHeader)
 
 	for i := 1; i < len(data); i++ {
 		departemenID, err := strconv.Atoi(data[i][1])
 
 		if err != nil {
-			util.LogErr("ERROR", "Invalid departemenID data in pengurus.csv file.", err.Error())
+			logger.Default().Error("Invalid departemenID data in pengurus.csv file.", logger.ErrorKey, err)
 			panic("Invalid departemenID data in pengurus.csv file.")
 		}
 
 		jabatanID, err := strconv.Atoi(data[i][2])
 
 		if err != nil {
-			util.LogErr("ERROR", "Invalid jabatanID data in pengurus.csv file.", err.Error())
+			logger.Default().Error("Invalid jabatanID data in pengurus.csv file.", logger.ErrorKey, err)
 			panic("Invalid jabatanID data in pengurus.csv file.")
 		}
 
@@ -120,30 +118,29 @@ func seedPengurus() {
 		result := db.DB.Create(&pengurus)
 
 		if result.Error != nil {
-			util.LogErr("ERROR", fmt.Sprintf("Failed to insert data Pengurus for %s", pengurus.NPM), result.Error.Error())
-			log.Printf("Failed to insert data Pengurus for %s because %s", pengurus.NPM, result.Error)
+			logger.Default().Error(fmt.Sprintf("Failed to insert data Pengurus for %s", pengurus.NPM), logger.ErrorKey, result.Error)
 		}
 	}
 }
 
 func seedJabatan() {
-	filepath := os.Getenv("JABATAN_SEEDER_DATA_PATH")
+	filepath := config.Get().Seeder.JabatanPath
 	data := readFromCSV(filepath)
 
-	validateCSVColumnHeader(data[0], "JABATAN_CSV_HEADER_CONFIG")
+	validateCSVColumnHeader(data[0], "JABATAN_CSV_HEADER_CONFIG", config.Get().Seeder.JabatanHeader)
 
 	for i := 1; i < len(data); i++ {
 		id, err := strconv.ParseInt(data[i][0], 10, 8)
 
 		if err != nil {
-			util.LogErr("ERROR", "Invalid type on JabatanID field from corespondense CSV file", err.Error())
+			logger.Default().Error("Invalid type on JabatanID field from corespondense CSV file", logger.ErrorKey, err)
 			panic("Invalid type on JabatanID field from corespondense CSV file")
 		}
 
 		privLevel, err := strconv.ParseInt(data[i][1], 10, 8)
 
 		if err != nil {
-			util.LogErr("ERROR", "Invalid type on privilegeLevel field from corespondense CSV file", err.Error())
+			logger.Default().Error("Invalid type on privilegeLevel field from corespondense CSV file", logger.ErrorKey, err)
 			panic("Invalid type on privilegeLevel field from corespondense CSV file")
 		}
 
@@ -156,17 +153,16 @@ func seedJabatan() {
 		result := db.DB.Create(&jabatan)
 
 		if result.Error != nil {
-			util.LogErr("ERROR", fmt.Sprintf("Failed to insert data Jabatan for %s", jabatan.Name), result.Error.Error())
-			log.Printf("Failed to insert data Jabatan for %s", jabatan.Name)
+			logger.Default().Error(fmt.Sprintf("Failed to insert data Jabatan for %s", jabatan.Name), logger.ErrorKey, result.Error)
 		}
 	}
 }
 
 func seedSuperAdminUser() {
-	filepath := os.Getenv("SUPER_ADMIN_SEEDER_DATA_PATH")
+	filepath := config.Get().Seeder.SuperAdminPath
 	data := readFromCSV(filepath)
 
-	validateCSVColumnHeader(data[0], "SUPER_ADMIN_CSV_HEADER_CONFIG")
+	validateCSVColumnHeader(data[0], "SUPER_ADMIN_CSV_HEADER_CONFIG", config.Get().Seeder.SuperAdminHeader)
 
 	for i := 1; i < len(data); i++ {
 		superAdmin := models.User{
@@ -177,8 +173,7 @@ func seedSuperAdminUser() {
 		result := db.DB.Create(&superAdmin)
 
 		if result.Error != nil {
-			util.LogErr("ERROR", fmt.Sprintf("User admin failed to insert in: %s", superAdmin.NPM), result.Error.Error())
-			log.Printf("User admin failed to insert in: %s", superAdmin.NPM)
+			logger.Default().Error(fmt.Sprintf("User admin failed to insert in: %s", superAdmin.NPM), logger.ErrorKey, result.Error)
 		}
 	}
 }
@@ -187,7 +182,7 @@ func readFromCSV(filepath string) [][]string {
 	file, err := os.Open(filepath)
 
 	if err != nil {
-		util.LogErr("ERROR", "Failed to open CSV seeder file", err.Error())
+		logger.Default().Error("Failed to open CSV seeder file", logger.ErrorKey, err)
 		log.Fatal("Failed to open CSV seeder file: ", filepath)
 	}
 
@@ -198,24 +193,22 @@ func readFromCSV(filepath string) [][]string {
 	data, err := csvData.ReadAll()
 
 	if err != nil {
-		util.LogErr("ERROR", "error accoured when reading CSV seeder file", err.Error())
+		logger.Default().Error("error accoured when reading CSV seeder file", logger.ErrorKey, err)
 		log.Fatal("error accoured when reading CSV seeder file: ", filepath)
 	}
 
 	return data
 }
 
-func validateCSVColumnHeader(firstRow []string, configName string) {
-	config := strings.Split(os.Getenv(configName), ",")
-
-	if len(config) != len(firstRow) {
-		util.LogErr("ERROR", fmt.Sprintf("Format mismatch in %s with the input CS file. Please read the seeder instruction carefully", configName), "")
+func validateCSVColumnHeader(firstRow []string, configName string, header []string) {
+	if len(header) != len(firstRow) {
+		logger.Default().Error(fmt.Sprintf("Format mismatch in %s with the input CS file. Please read the seeder instruction carefully", configName))
 		log.Fatal(fmt.Sprintf("Format mismatch in %s with the input CS file. Please read the seeder instruction carefully.", configName))
 	}
 
-	for i, s := range config {
+	for i, s := range header {
 		if s != firstRow[i] {
-			util.LogErr("ERROR", fmt.Sprintf("CSV header format mismatch: %s with %s while checking validity in: %s", s, firstRow[i], configName), "")
+			logger.Default().Error(fmt.Sprintf("CSV header format mismatch: %s with %s while checking validity in: %s", s, firstRow[i], configName))
 			log.Fatal(fmt.Sprintf("CSV header format mismatch: %s with %s while checking validity in: %s", s, firstRow[i], configName))
 		}
 	}
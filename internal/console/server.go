@@ -1,15 +1,29 @@
 package console
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/config"
+	"himatro-api/internal/controller"
 	"himatro-api/internal/db"
+	"himatro-api/internal/handler"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/middleware"
+	"himatro-api/internal/repository"
 	"himatro-api/internal/router"
-	"himatro-api/internal/util"
+	"himatro-api/internal/servertls"
+	"himatro-api/internal/tracing"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/labstack/echo/v4"
 	"github.com/spf13/cobra"
 )
 
@@ -25,19 +39,240 @@ func init() {
 }
 
 func InitServer(cmd *cobra.Command, args []string) {
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Options{
+		Enabled:     config.Get().Tracing.Enabled,
+		Endpoint:    config.Get().Tracing.Endpoint,
+		Insecure:    config.Get().Tracing.Insecure,
+		SampleRatio: config.Get().Tracing.SampleRatio,
+	})
+
+	if err != nil {
+		logger.Default().Error("Tracing failed to start", logger.ErrorKey, err)
+		log.Fatal("Tracing failed to start.", err)
+	}
+
 	db.Connect()
 
-	r := router.Router()
-	s := http.Server{
-		Addr:    os.Getenv("SERVER_PORT"),
-		Handler: r,
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	servers := []*http.Server{}
+
+	if addr := config.Get().Metrics.Addr; addr != "" {
+		servers = append(servers, serveMetrics(addr))
+	}
+
+	store := repository.NewGormStore(db.DB)
+	clk := clock.New()
+
+	cfg := config.Get().Server
+	s := newHTTPServer(cfg, router.Router(store, clk, logger.Default()))
+	servers = append(servers, s)
+
+	go cleanIdempotencyKeys(ctx, controller.NewIdempotencyController(store.IdempotencyKeys(), clk, logger.Default()), config.Get().Idempotency.CleanupInterval)
+
+	if tlsCfg := config.Get().TLS; tlsCfg.Enabled() {
+		redirect, err := setupTLS(s, cfg, tlsCfg)
+
+		if err != nil {
+			logger.Default().Error("TLS failed to start", logger.ErrorKey, err)
+			log.Fatal("TLS failed to start.", err)
+		}
+
+		if redirect != nil {
+			servers = append(servers, redirect)
+		}
 	}
 
-	log.Print(fmt.Sprintf("Server listening on port %s", s.Addr))
-	err := s.ListenAndServe()
+	listener, err := listen(cfg)
 
 	if err != nil {
-		util.LogErr("ERROR", "SERVER failed to start", err.Error())
+		logger.Default().Error("SERVER failed to start", logger.ErrorKey, err)
 		log.Fatal("Server failed to starts.", err)
 	}
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		logger.Default().Info("Server listening", "addr", listener.Addr().String(), "tls", s.TLSConfig != nil)
+
+		if s.TLSConfig != nil {
+			serveErr <- s.ServeTLS(listener, "", "")
+		} else {
+			serveErr <- s.Serve(listener)
+		}
+	}()
+
+	select {
+	case err = <-serveErr:
+		logger.Default().Error("SERVER failed to start", logger.ErrorKey, err)
+
+		// The metrics and redirect servers still run, drain them as well.
+		if shutdownErr := shutdown(servers, cfg.ShutdownTimeout); shutdownErr != nil {
+			logger.Default().Error("Failed to shut down the other servers", logger.ErrorKey, shutdownErr)
+		}
+	case <-ctx.Done():
+		logger.Default().Info("Shutting down, waiting for requests in flight", "timeout", cfg.ShutdownTimeout)
+		err = shutdown(servers, cfg.ShutdownTimeout)
+	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		logger.Default().Error("Failed to flush traces", logger.ErrorKey, err)
+	}
+
+	if sqlDB, dbErr := db.DB.DB(); dbErr == nil {
+		sqlDB.Close()
+	}
+
+	if err != nil {
+		log.Fatal("Server stopped with an error.", err)
+	}
+
+	logger.Default().Info("Server stopped")
+}
+
+// cleanIdempotencyKeys deletes the expired idempotency keys every interval until
+// ctx is done.
+func cleanIdempotencyKeys(ctx context.Context, keys *controller.IdempotencyController, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// The error is logged by the controller, the next tick tries again.
+			_ = keys.DeleteExpiredIdempotencyKeys(ctx)
+		}
+	}
+}
+
+func newHTTPServer(cfg config.Server, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// setupTLS makes s serve HTTPS with HTTP/2, and starts the plain HTTP server
+// that redirects to it and answers the ACME challenges, when one is configured.
+func setupTLS(s *http.Server, cfg config.Server, tlsCfg config.TLS) (*http.Server, error) {
+	opts := servertls.Options{
+		ACMEEmail:        tlsCfg.ACMEEmail,
+		ACMECacheDir:     tlsCfg.ACMECacheDir,
+		ACMEDirectoryURL: tlsCfg.ACMEDirectoryURL,
+	}
+
+	if tlsCfg.Mode == config.TLSACME {
+		opts.ACMEDomains = tlsCfg.ACMEDomains
+	} else {
+		opts.CertFile = tlsCfg.CertFile
+		opts.KeyFile = tlsCfg.KeyFile
+	}
+
+	if _, port, err := net.SplitHostPort(cfg.Addr); err == nil && cfg.Socket == "" {
+		opts.HTTPSPort = port
+	}
+
+	tlsConfig, redirectHandler, err := servertls.Setup(opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.TLSConfig = tlsConfig
+
+	if tlsCfg.RedirectAddr == "" {
+		return nil, nil
+	}
+
+	redirectCfg := cfg
+	redirectCfg.Addr = tlsCfg.RedirectAddr
+	redirect := newHTTPServer(redirectCfg, redirectHandler)
+
+	go func() {
+		logger.Default().Info("HTTP to HTTPS redirect listening", "addr", redirect.Addr)
+
+		if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Default().Error("HTTP to HTTPS redirect failed to start", logger.ErrorKey, err)
+		}
+	}()
+
+	return redirect, nil
+}
+
+// listen opens the Unix socket when one is configured, otherwise the TCP address.
+// A socket file left behind by a crashed server is removed first.
+func listen(cfg config.Server) (net.Listener, error) {
+	if cfg.Socket == "" {
+		return net.Listen("tcp", cfg.Addr)
+	}
+
+	if err := os.Remove(cfg.Socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", cfg.Socket)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(cfg.Socket, cfg.SocketFileMode()); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// shutdown stops accepting connections and waits up to timeout for the requests
+// in flight, then closes whatever is left.
+func shutdown(servers []*http.Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	errs := []error{}
+
+	for _, s := range servers {
+		if err := s.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+			s.Close()
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// serveMetrics exposes /metrics on its own listen address, so it can be kept
+// away from the public Nginx upstream.
+func serveMetrics(addr string) *http.Server {
+	e := echo.New()
+	e.HideBanner = true
+
+	if config.Get().Metrics.APIKey != "" {
+		e.GET("/metrics", handler.Metrics, middleware.RequireMetricsKey)
+	} else {
+		e.GET("/metrics", handler.Metrics)
+	}
+
+	cfg := config.Get().Server
+	cfg.Addr = addr
+	s := newHTTPServer(cfg, e)
+
+	go func() {
+		logger.Default().Info("Metrics server listening", "addr", addr)
+
+		if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Default().Error("Metrics server failed to start", logger.ErrorKey, err)
+		}
+	}()
+
+	return s
 }
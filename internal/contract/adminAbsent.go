@@ -1,14 +1,21 @@
 package contract
 
+// CreateAbsentForm takes each schedule time either as an RFC 3339 timestamp
+// (startAt) or as a YYYY-MM-DD date and a HH:MM[:SS] time on the wall clock of
+// TimeZone (startAtDate and startAtTime). The date and time win when both are set.
 type CreateAbsentForm struct {
 	Title                       string `json:"title" validate:"required"`
-	StartAtDate                 string `json:"startAtDate" validate:"required"`
-	StartAtTime                 string `json:"startAtTime" validate:"required"`
-	FinishAtDate                string `json:"finishAtDate" validate:"required"`
-	FinishAtTime                string `json:"finishAtTime" validate:"required"`
+	StartAt                     string `json:"startAt,omitempty" validate:"required_without_all=StartAtDate StartAtTime"`
+	StartAtDate                 string `json:"startAtDate,omitempty" validate:"required_without=StartAt"`
+	StartAtTime                 string `json:"startAtTime,omitempty" validate:"required_without=StartAt"`
+	FinishAt                    string `json:"finishAt,omitempty" validate:"required_without_all=FinishAtDate FinishAtTime"`
+	FinishAtDate                string `json:"finishAtDate,omitempty" validate:"required_without=FinishAt"`
+	FinishAtTime                string `json:"finishAtTime,omitempty" validate:"required_without=FinishAt"`
+	TimeZone                    string `json:"timeZone,omitempty" validate:"omitempty,timezone"`
 	RequireAttendanceImageProof bool   `json:"requireAttendanceImageProof,omitempty"`
 	RequireExecuseImageProof    bool   `json:"requireExecuseImageProof,omitempty"`
 	Participant                 string `json:"participant" validate:"required"`
+	ResultVisibility            string `json:"resultVisibility,omitempty" validate:"omitempty,oneof=public members admins masked"`
 }
 
 type UpdateFormTitle struct {
@@ -23,7 +30,14 @@ type UpdateFormImageProof struct {
 	Status bool `json:"status"`
 }
 
+// UpdateFormTime is either an RFC 3339 timestamp (at) or a date and a time on the
+// wall clock of the form's time zone.
 type UpdateFormTime struct {
-	Date string `json:"date" validate:"required"`
-	Time string `json:"time" validate:"required"`
+	At   string `json:"at,omitempty" validate:"required_without_all=Date Time"`
+	Date string `json:"date,omitempty" validate:"required_without=At"`
+	Time string `json:"time,omitempty" validate:"required_without=At"`
+}
+
+type UpdateFormResultVisibility struct {
+	Visibility string `json:"visibility" validate:"required,oneof=public members admins masked"`
 }
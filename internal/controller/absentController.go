@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"himatro-api/internal/clock"
+	"himatro-api/internal/repository"
+	"log/slog"
+)
+
+// AbsentController handles absent forms and their absent lists.
+type AbsentController struct {
+	store repository.Store
+	clock clock.Clock
+	log   *slog.Logger
+}
+
+func NewAbsentController(store repository.Store, clk clock.Clock, log *slog.Logger) *AbsentController {
+	return &AbsentController{store: store, clock: clk, log: log}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/models"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
+)
+
+// ArchiveAbsentForm soft deletes the form with its absent list, which
+// RestoreAbsentForm brings back as it was. A non zero version must match the
+// current version of the form, archiving bumps it.
+func (a *AbsentController) ArchiveAbsentForm(ctx context.Context, formID int, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.ArchiveAbsentForm")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	archived := models.FormAbsensi{}
+
+	err := a.store.Transaction(ctx, func(tx repository.Store) error {
+		formAbsent, err := a.findFormAbsent(ctx, tx, formID)
+
+		if err != nil {
+			return err
+		}
+
+		if err := a.checkFormVersion(ctx, formAbsent, version); err != nil {
+			return err
+		}
+
+		now := a.clock.Now()
+
+		if err := tx.Forms().Archive(ctx, formAbsent.ID, now); err != nil {
+			log.Error("Failed to archive absent form", logger.FormIDKey, formID, logger.ErrorKey, err)
+			return apperr.Internal(err, "server failure to archive absent form")
+		}
+
+		formAbsent.DeletedAt.Time = now
+		formAbsent.DeletedAt.Valid = true
+		formAbsent.Version++
+		archived = formAbsent
+
+		return nil
+	})
+
+	if err != nil {
+		log.Info("Failed to archive absent form", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	log.Info("Absent form archived", logger.FormIDKey, formID)
+
+	return absentFormRepresentation(archived), nil
+}
+
+// RestoreAbsentForm unarchives the form. Restoring a form that isn't archived
+// returns it unchanged.
+func (a *AbsentController) RestoreAbsentForm(ctx context.Context, formID int) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.RestoreAbsentForm")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	err := a.store.Forms().Restore(ctx, uint(formID))
+
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Error("Failed to restore absent form", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, apperr.Internal(err, "server failure to restore absent form")
+	}
+
+	formAbsent, err := a.getFormDetail(ctx, formID)
+
+	if err != nil {
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	log.Info("Absent form restored", logger.FormIDKey, formID)
+
+	return absentFormRepresentation(formAbsent), nil
+}
+
+// PurgeArchivedForms permanently deletes the forms archived for longer than
+// retention, with their absent lists.
+func (a *AbsentController) PurgeArchivedForms(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := tracing.Start(ctx, "controller.PurgeArchivedForms")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	cutoff := a.clock.Now().Add(-retention)
+
+	purged, err := a.store.Forms().PurgeArchived(ctx, cutoff)
+
+	if err != nil {
+		log.Error("Failed to purge archived absent forms", "archivedBefore", cutoff, logger.ErrorKey, err)
+		return 0, apperr.Internal(err, "failed to purge archived absent forms")
+	}
+
+	log.Info("Archived absent forms purged", "archivedBefore", cutoff, "purged", purged)
+
+	return purged, nil
+}
@@ -1,177 +1,186 @@
 package controller
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"himatro-api/internal/apperr"
 	"himatro-api/internal/auth"
-	"himatro-api/internal/db"
+	"himatro-api/internal/logger"
 	"himatro-api/internal/models"
-	"himatro-api/internal/util"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
 	"net/http"
-	"time"
 )
 
-func FillAbsentForm(absentID int, NPM string, keterangan string) (string, error) {
-	pengurus, err := getPengurusData(NPM)
+func (a *AbsentController) FillAbsentForm(ctx context.Context, absentID int, NPM string, keterangan string) (string, error) {
+	ctx, span := tracing.Start(ctx, "controller.FillAbsentForm")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	pengurus, err := a.getPengurusData(ctx, NPM)
 
 	if err != nil {
-		util.LogErr("WARN", "Absent filling failed", err.Error())
+		log.Info("Absent filling failed", logger.FormIDKey, absentID, logger.NPMKey, NPM, logger.ErrorKey, err)
 		return "", err
 	}
 
-	formDetail, err := getFormAbsentDetail(absentID)
+	formDetail, err := a.getFormAbsentDetail(ctx, absentID)
 
 	if err != nil {
-		util.LogErr("WARN", "Absent filling failed", err.Error())
+		log.Info("Absent filling failed", logger.FormIDKey, absentID, logger.NPMKey, NPM, logger.ErrorKey, err)
 		return "", err
 	}
 
 	if formDetail.Participant != pengurus.DepartemenID && formDetail.Participant != 0 {
-		util.LogErr("WARN", fmt.Sprintf("Unexpected attendance on absentID: %d", absentID), NPM)
-		return "", fmt.Errorf("you are not the expected attendance of this absent form")
+		log.Info("Unexpected attendance on absent form", logger.FormIDKey, absentID, logger.NPMKey, NPM)
+		return "", apperr.Forbidden(apperr.CodeNotParticipant, "you are not the expected attendance of this absent form")
+	}
+
+	if err := a.isAlreadyAttend(ctx, absentID, NPM); err != nil {
+		log.Info("Attendant already filled the absent form", logger.FormIDKey, absentID, logger.NPMKey, NPM, logger.ErrorKey, err)
+		return "", err
 	}
 
-	if err := isAlreadyAttend(absentID, NPM); err != nil {
-		util.LogErr("WARN", fmt.Sprintf("%s already attend absentID: %d", NPM, absentID), err.Error())
+	if err := a.saveAttendanceRecord(ctx, absentID, NPM, keterangan); err != nil {
 		return "", err
 	}
 
-	saveAttendanceRecord(absentID, NPM, keterangan)
-	updateToken, err := auth.CreateUpdateAbsentListToken(absentID, NPM)
+	updateToken, err := auth.CreateUpdateAbsentListToken(a.clock, absentID, NPM)
 
 	if err != nil {
-		util.LogErr("ERROR", "Failed to create update absent list token", err.Error())
-		return "", nil
+		log.Error("Failed to create update absent list token", logger.FormIDKey, absentID, logger.NPMKey, NPM, logger.ErrorKey, err)
+		return "", apperr.Internal(err, "system failure to create update token")
 	}
 
+	log.Info("Attendance recorded", logger.FormIDKey, absentID, logger.NPMKey, NPM, "keterangan", keterangan)
+
 	return updateToken, nil
 }
 
-func IsFormWriteable(absentID int) error {
-	formAbsensi, err := getFormAbsentDetail(absentID)
+func (a *AbsentController) IsFormWriteable(ctx context.Context, absentID int) error {
+	ctx, span := tracing.Start(ctx, "controller.IsFormWriteable")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	formAbsensi, err := a.getFormAbsentDetail(ctx, absentID)
 
 	if err != nil {
-		util.LogErr("WARN", "Failed to lookup the absent form", err.Error())
+		log.Info("Failed to lookup the absent form", logger.FormIDKey, absentID, logger.ErrorKey, err)
 		return err
 	}
 
-	if formAbsensi.StartAt.After(time.Now()) {
-		util.LogErr("WARN", fmt.Sprintf("Accessing to early absent form: %d", absentID), "")
-		return fmt.Errorf("absent form with ID: %d is not open yet", absentID)
+	if formAbsensi.StartAt.After(a.clock.Now()) {
+		log.Info("Accessing too early absent form", logger.FormIDKey, absentID)
+		return apperr.Forbidden(apperr.CodeFormNotOpen, "absent form with ID: %d is not open yet", absentID)
 	}
 
-	if formAbsensi.FinishAt.Before(time.Now()) {
-		util.LogErr("WARN", fmt.Sprintf("Accessing closed absent form: %d", absentID), "")
-		return fmt.Errorf("absent form with ID: %d is already closed", absentID)
+	if formAbsensi.FinishAt.Before(a.clock.Now()) {
+		log.Info("Accessing closed absent form", logger.FormIDKey, absentID)
+		return apperr.Forbidden(apperr.CodeFormClosed, "absent form with ID: %d is already closed", absentID)
 	}
 
 	return nil
 }
 
-func UpdateAbsentListByAttendant(absentID int, keterangan string, cookie *http.Cookie) error {
+func (a *AbsentController) UpdateAbsentListByAttendant(ctx context.Context, absentID int, keterangan string, cookie *http.Cookie) error {
+	ctx, span := tracing.Start(ctx, "controller.UpdateAbsentListByAttendant")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
 	tokenPayload := auth.UpdateAbsentListClaims{}
 
-	if err := auth.ExtractJWTPayload(cookie.Value, &tokenPayload); err != nil {
-		util.LogErr("WARN", "failed to update absent list by attendant", err.Error())
-		return fmt.Errorf("update absent failed because: %s", err.Error())
+	if err := auth.ExtractJWTPayload(a.clock, cookie.Value, &tokenPayload); err != nil {
+		log.Info("Failed to update absent list by attendant", logger.FormIDKey, absentID, logger.ErrorKey, err)
+		return apperr.Unauthorized(apperr.CodeInvalidToken, "update absent failed because: %s", err.Error())
 	}
 
 	if absentID != int(tokenPayload.AbsentID) {
-		util.LogErr("WARN", "token mismatch with absentID requested", fmt.Sprintf("absentID: %d", absentID))
-		return fmt.Errorf("token mismatch with absentID requested")
+		log.Warn("Token mismatch with absentID requested", logger.FormIDKey, absentID, logger.NPMKey, tokenPayload.NPM)
+		return apperr.Forbidden(apperr.CodeTokenMismatch, "token mismatch with absentID requested")
 	}
 
-	updateAttendanceRecord(absentID, tokenPayload.NPM, keterangan)
-
-	return nil
+	return a.updateAttendanceRecord(ctx, absentID, tokenPayload.NPM, keterangan)
 }
 
-func getFormAbsentDetail(absentID int) (models.FormAbsensi, error) {
-	formAbsent := models.FormAbsensi{}
+func (a *AbsentController) getFormAbsentDetail(ctx context.Context, absentID int) (models.FormAbsensi, error) {
+	log := loggerFrom(ctx, a.log)
 
-	res := db.DB.Model(&models.FormAbsensi{}).
-		Where("id = ?", absentID).
-		First(&formAbsent)
+	formAbsent, err := a.store.Forms().FindByID(ctx, uint(absentID))
 
-	if res.Error != nil {
-		util.LogErr("WARN", fmt.Sprintf("absent form not found at absentID: %d", absentID), "")
-		return models.FormAbsensi{}, fmt.Errorf("absent form with ID: %d is not exists", absentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Absent form not found", logger.FormIDKey, absentID)
+		return models.FormAbsensi{}, apperr.NotFound(apperr.CodeFormNotFound, "absent form with ID: %d is not exists", absentID)
+	}
+
+	if err != nil {
+		log.Error("Failed to lookup the absent form", logger.FormIDKey, absentID, logger.ErrorKey, err)
+		return models.FormAbsensi{}, apperr.Internal(err, "system failure to lookup absent form")
 	}
 
 	return formAbsent, nil
 }
 
-func getPengurusData(NPM string) (models.Pengurus, error) {
-	pengurus := models.Pengurus{
-		NPM: NPM,
-	}
+func (a *AbsentController) getPengurusData(ctx context.Context, NPM string) (models.Pengurus, error) {
+	log := loggerFrom(ctx, a.log)
+
+	pengurus, err := a.store.Members().FindPengurus(ctx, NPM)
 
-	res := db.DB.Model(&models.Pengurus{}).
-		Where(&models.Pengurus{
-			NPM: NPM,
-		}).
-		First(&pengurus)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Pengurus not found", logger.NPMKey, NPM)
+		return pengurus, apperr.NotFound(apperr.CodePengurusNotFound, "pengurus with NPM: %s is not found", NPM)
+	}
 
-	if res.Error != nil {
-		util.LogErr("WARN", fmt.Sprintf("pengurus with NPM: %s is not found", NPM), res.Error.Error())
-		return pengurus, fmt.Errorf("pengurus with NPM: %s is not found", NPM)
+	if err != nil {
+		log.Error("Failed to lookup pengurus", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return pengurus, apperr.Internal(err, "system failure to lookup pengurus")
 	}
 
 	return pengurus, nil
 }
 
-func isAlreadyAttend(absentID int, NPM string) error {
-	absentList := models.AbsentList{}
+func (a *AbsentController) isAlreadyAttend(ctx context.Context, absentID int, NPM string) error {
+	log := loggerFrom(ctx, a.log)
+
+	absentList, err := a.store.AbsentLists().Find(ctx, uint(absentID), NPM)
 
-	res := db.DB.Model(&models.AbsentList{}).
-		Where(&models.AbsentList{
-			FormAbsensiID: uint(absentID),
-			NPM:           NPM,
-		}).First(&absentList)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Attendant is not listed on the absent form", logger.FormIDKey, absentID, logger.NPMKey, NPM)
+		return apperr.Forbidden(apperr.CodeNotParticipant, "you are not the expected attendance of this absent form")
+	}
 
-	if res.Error != nil {
-		util.LogErr("ERROR", fmt.Sprintf("failed to fill attendance record for %d - %s", absentID, NPM), res.Error.Error())
-		return errors.New("failed to fill attendance record")
+	if err != nil {
+		log.Error("Failed to fill attendance record", logger.FormIDKey, absentID, logger.NPMKey, NPM, logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to fill attendance record")
 	}
 
 	if absentList.Keterangan != "?" {
-		util.LogErr("WARN", fmt.Sprintf("attendant with NPM: %s is alredy filled this form", NPM), "")
-		return fmt.Errorf("attendant with NPM: %s is alredy filled this form", NPM)
+		log.Info("Attendant already filled the absent form", logger.FormIDKey, absentID, logger.NPMKey, NPM)
+		return apperr.Conflict(apperr.CodeAlreadyFilled, "attendant with NPM: %s is alredy filled this form", NPM)
 	}
 
 	return nil
 }
 
-func saveAttendanceRecord(absentID int, NPM string, keterangan string) error {
-	res := db.DB.Model(&models.AbsentList{}).
-		Where(&models.AbsentList{
-			FormAbsensiID: uint(absentID),
-			NPM:           NPM,
-		}).
-		Update("keterangan", keterangan)
+func (a *AbsentController) saveAttendanceRecord(ctx context.Context, absentID int, NPM string, keterangan string) error {
+	log := loggerFrom(ctx, a.log)
 
-	if res.Error != nil {
-		util.LogErr("ERROR", fmt.Sprintf("failed to fill attendance record for %d - %s", absentID, NPM), res.Error.Error())
-		return errors.New("system failure to save absent record")
+	if err := a.store.AbsentLists().UpdateKeterangan(ctx, uint(absentID), NPM, keterangan); err != nil {
+		log.Error("Failed to fill attendance record", logger.FormIDKey, absentID, logger.NPMKey, NPM, logger.ErrorKey, err)
+		return apperr.Internal(err, "system failure to save absent record")
 	}
 
 	return nil
 }
 
-func updateAttendanceRecord(absentID int, NPM string, keterangan string) error {
-	absentList := models.AbsentList{
-		FormAbsensiID: uint(absentID),
-		NPM:           NPM,
-	}
-
-	res := db.DB.Model(&models.AbsentList{}).
-		Where(&absentList).
-		First(&absentList).
-		Update("keterangan", keterangan)
+func (a *AbsentController) updateAttendanceRecord(ctx context.Context, absentID int, NPM string, keterangan string) error {
+	log := loggerFrom(ctx, a.log)
 
-	if res.Error != nil {
-		util.LogErr("ERROR", fmt.Sprintf("failed to fill attendance record for %d - %s", absentID, NPM), res.Error.Error())
-		return errors.New("server failed to update absent list")
+	if err := a.store.AbsentLists().UpdateKeterangan(ctx, uint(absentID), NPM, keterangan); err != nil {
+		log.Error("Failed to fill attendance record", logger.FormIDKey, absentID, logger.NPMKey, NPM, logger.ErrorKey, err)
+		return apperr.Internal(err, "server failed to update absent list")
 	}
 
 	return nil
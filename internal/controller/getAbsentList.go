@@ -1,85 +1,165 @@
 package controller
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"himatro-api/internal/db"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/auth"
+	"himatro-api/internal/logger"
 	"himatro-api/internal/models"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
 	"himatro-api/internal/util"
+	"net/http"
 )
 
-func GetAbsentFormsDetails(limit int) ([]models.ReturnedFormAbsentDetails, error) {
-	absentFormsDetails := []models.ReturnedFormAbsentDetails{}
-
-	res := db.DB.Model(&models.FormAbsensi{}).
-		Select(`
-			form_absensis.id as form_id,
-			form_absensis.title,
-			form_absensis.created_at,
-			form_absensis.updated_at,
-			form_absensis.participant as participant_code,
-			form_absensis.start_at,
-			form_absensis.finish_at,
-			form_absensis.require_attendance_image_proof,
-			form_absensis.require_execuse_image_proof,
-			count(absent_lists.id) as total_participant,
-			count(absent_lists.keterangan) filter(where absent_lists.keterangan = 'h') as hadir,
-			count(absent_lists.keterangan) filter(where absent_lists.keterangan = 'i') as izin,
-			count(absent_lists.keterangan) filter (where absent_lists.keterangan = '?') as tanpa_keterangan
-		`).
-		Limit(limit).
-		Joins("inner join absent_lists on absent_lists.form_absensi_id = form_absensis.id").
-		Group("form_id").
-		Scan(&absentFormsDetails)
-
-	if res.Error != nil {
-		util.LogErr("ERROR", "failed to query absent forms details", res.Error.Error())
-		return []models.ReturnedFormAbsentDetails{}, errors.New("failed to query absent forms details")
+// GetAbsentFormsDetails lists the forms with their attendance counts, archived
+// forms only when includeArchived is set.
+func (a *AbsentController) GetAbsentFormsDetails(ctx context.Context, limit int, includeArchived bool) ([]models.ReturnedFormAbsentDetails, error) {
+	ctx, span := tracing.Start(ctx, "controller.GetAbsentFormsDetails")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	absentFormsDetails, err := a.store.Forms().ListDetails(ctx, limit, includeArchived)
+
+	if err != nil {
+		log.Error("Failed to query absent forms details", logger.ErrorKey, err)
+		return []models.ReturnedFormAbsentDetails{}, apperr.Internal(err, "failed to query absent forms details")
 	}
 
-	return absentFormsDetails, nil
+	for i, detail := range absentFormsDetails {
+		loc := formLocation(detail.TimeZone)
+
+		absentFormsDetails[i].StartAt = detail.StartAt.In(loc)
+		absentFormsDetails[i].FinishAt = detail.FinishAt.In(loc)
+		absentFormsDetails[i].TimeZone = formTimeZone(detail.TimeZone)
+	}
 
+	return absentFormsDetails, nil
 }
 
-func GetAbsentListResult(absentID int) ([]models.ReturnedAbsentList, error) {
-	if err := isFormAbsentExists(absentID); err != nil {
-		util.LogErr("WARN", fmt.Sprintf("Form absent not found ID: %d", absentID), err.Error())
+func (a *AbsentController) GetAbsentListResult(ctx context.Context, absentID int) ([]models.ReturnedAbsentList, error) {
+	ctx, span := tracing.Start(ctx, "controller.GetAbsentListResult")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	if err := a.isFormAbsentExists(ctx, absentID); err != nil {
+		log.Info("Absent form not found", logger.FormIDKey, absentID, logger.ErrorKey, err)
 		return []models.ReturnedAbsentList{}, err
 	}
 
-	absentList, err := getAbsentListFromFormID(absentID)
+	absentList, err := a.getAbsentListFromFormID(ctx, absentID)
 
 	if err != nil {
-		util.LogErr("ERROR", fmt.Sprintf("failed to get absent result list ID: %d", absentID), err.Error())
+		log.Error("Failed to get absent result list", logger.FormIDKey, absentID, logger.ErrorKey, err)
 		return []models.ReturnedAbsentList{}, err
 	}
 
 	return absentList, nil
 }
 
-func isFormAbsentExists(absentID int) error {
-	formAbsent := models.FormAbsensi{}
+func (a *AbsentController) GetAbsentForm(ctx context.Context, absentID int) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.GetAbsentForm")
+	defer span.End()
+
+	formAbsent, err := a.getFormDetail(ctx, absentID)
+
+	if err != nil {
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	return absentFormRepresentation(formAbsent), nil
+}
+
+func (a *AbsentController) GetFormResultVisibility(ctx context.Context, absentID int) (string, error) {
+	ctx, span := tracing.Start(ctx, "controller.GetFormResultVisibility")
+	defer span.End()
 
-	res := db.DB.Model(&models.FormAbsensi{}).
-		Where("id = ?", absentID).
-		First(&formAbsent)
+	log := loggerFrom(ctx, a.log)
 
-	if res.Error != nil {
-		util.LogErr("WARN", fmt.Sprintf("absent form with ID: %d is not exists", absentID), res.Error.Error())
-		return fmt.Errorf("absent form with ID: %d is not exists", absentID)
+	formAbsent, err := a.getFormAbsentDetail(ctx, absentID)
+
+	if err != nil {
+		log.Info("Absent form not found", logger.FormIDKey, absentID, logger.ErrorKey, err)
+		return "", err
+	}
+
+	if formAbsent.ResultVisibility == "" {
+		return models.ResultVisibilityPublic, nil
+	}
+
+	return formAbsent.ResultVisibility, nil
+}
+
+func (a *AbsentController) ValidateMemberToken(ctx context.Context, cookie *http.Cookie) error {
+	ctx, span := tracing.Start(ctx, "controller.ValidateMemberToken")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	tokenPayload := auth.UpdateAbsentListClaims{}
+
+	if err := auth.ExtractJWTPayload(a.clock, cookie.Value, &tokenPayload); err != nil {
+		log.Info("Member token is invalid", logger.ErrorKey, err)
+		return apperr.Unauthorized(apperr.CodeInvalidToken, "member token is invalid")
+	}
+
+	_, err := a.store.Members().FindAnggota(ctx, tokenPayload.NPM)
+
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Member not found", logger.NPMKey, tokenPayload.NPM)
+		return apperr.Forbidden(apperr.CodeMemberNotFound, "member with NPM: %s is not found", tokenPayload.NPM)
+	}
+
+	if err != nil {
+		log.Error("Failed to lookup member", logger.NPMKey, tokenPayload.NPM, logger.ErrorKey, err)
+		return apperr.Internal(err, "system failure to lookup member")
 	}
 
 	return nil
 }
 
-func getAbsentListFromFormID(absentID int) ([]models.ReturnedAbsentList, error) {
-	absentLists := []models.ReturnedAbsentList{}
+func MaskAbsentListResult(absentLists []models.ReturnedAbsentList) []models.ReturnedAbsentList {
+	masked := []models.ReturnedAbsentList{}
+
+	for _, absentList := range absentLists {
+		absentList.NPM = util.MaskNPM(absentList.NPM)
+		absentList.Nama = util.NameInitials(absentList.Nama)
+
+		masked = append(masked, absentList)
+	}
+
+	return masked
+}
 
-	res := db.DB.Model(&models.AbsentList{}).Select("anggota_biasas.nama, absent_lists.npm, absent_lists.updated_at, absent_lists.keterangan, departemens.nama as nama_departemen").Where(&models.AbsentList{FormAbsensiID: uint(absentID)}).Joins("inner join anggota_biasas on anggota_biasas.npm = absent_lists.npm").Joins("inner join pengurus on pengurus.npm = anggota_biasas.npm").Joins("inner join departemens on departemens.id = pengurus.departemen_id").Find(&absentLists)
+func (a *AbsentController) isFormAbsentExists(ctx context.Context, absentID int) error {
+	log := loggerFrom(ctx, a.log)
 
-	if res.Error != nil {
-		util.LogErr("ERROR", fmt.Sprintf("server failed to fetch requested absent list ID: %d", absentID), res.Error.Error())
-		return absentLists, errors.New("server failed to fetch requested absent list")
+	_, err := a.store.Forms().FindByID(ctx, uint(absentID))
+
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Absent form not found", logger.FormIDKey, absentID)
+		return apperr.NotFound(apperr.CodeFormNotFound, "absent form with ID: %d is not exists", absentID)
+	}
+
+	if err != nil {
+		log.Error("Failed to lookup the absent form", logger.FormIDKey, absentID, logger.ErrorKey, err)
+		return apperr.Internal(err, "system failure to lookup absent form")
+	}
+
+	return nil
+}
+
+func (a *AbsentController) getAbsentListFromFormID(ctx context.Context, absentID int) ([]models.ReturnedAbsentList, error) {
+	log := loggerFrom(ctx, a.log)
+
+	absentLists, err := a.store.AbsentLists().ListResult(ctx, uint(absentID))
+
+	if err != nil {
+		log.Error("Failed to fetch requested absent list", logger.FormIDKey, absentID, logger.ErrorKey, err)
+		return absentLists, apperr.Internal(err, "server failed to fetch requested absent list")
 	}
 
 	return absentLists, nil
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"himatro-api/internal/config"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+type ComponentHealth struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+const readinessCheckTimeout = 2 * time.Second
+
+// HealthController reports whether the API is ready to serve requests.
+type HealthController struct {
+	store         repository.Store
+	schemaVersion uint
+	log           *slog.Logger
+}
+
+// NewHealthController reports the API ready only once the database is migrated
+// to schemaVersion, the newest migration built into the binary.
+func NewHealthController(store repository.Store, schemaVersion uint, log *slog.Logger) *HealthController {
+	return &HealthController{store: store, schemaVersion: schemaVersion, log: log}
+}
+
+// CheckReadiness checks every component the API needs to serve requests.
+// It returns false when at least one of them is not healthy. The response is
+// public, so the messages never hold the underlying errors, which are logged.
+func (h *HealthController) CheckReadiness(ctx context.Context) (map[string]ComponentHealth, bool) {
+	ctx, span := tracing.Start(ctx, "controller.CheckReadiness")
+	defer span.End()
+
+	log := loggerFrom(ctx, h.log)
+
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	components := map[string]ComponentHealth{
+		"database":   h.checkDatabase(ctx),
+		"migrations": h.checkMigrations(ctx),
+		"storage":    h.checkStorage(ctx),
+	}
+
+	ready := true
+
+	for name, component := range components {
+		if component.Status != HealthStatusOK {
+			log.Warn("Readiness check failed", "component", name, "reason", component.Message)
+			ready = false
+		}
+	}
+
+	return components, ready
+}
+
+func (h *HealthController) checkDatabase(ctx context.Context) ComponentHealth {
+	if err := h.store.Ping(ctx); err != nil {
+		loggerFrom(ctx, h.log).Error("Database is unreachable", logger.ErrorKey, err)
+		return ComponentHealth{Status: HealthStatusFail, Message: "database is unreachable"}
+	}
+
+	return ComponentHealth{Status: HealthStatusOK}
+}
+
+func (h *HealthController) checkMigrations(ctx context.Context) ComponentHealth {
+	log := loggerFrom(ctx, h.log)
+
+	version, err := h.store.SchemaVersion(ctx)
+
+	if err != nil {
+		log.Error("Failed to read the schema version", logger.ErrorKey, err)
+		return ComponentHealth{Status: HealthStatusFail, Message: "schema version is unknown, run migrate"}
+	}
+
+	if version != h.schemaVersion {
+		log.Warn("Database schema is not at the expected version", "version", version, "expected", h.schemaVersion)
+		return ComponentHealth{Status: HealthStatusFail, Message: fmt.Sprintf("schema is at version %d, expected %d, run migrate", version, h.schemaVersion)}
+	}
+
+	return ComponentHealth{Status: HealthStatusOK}
+}
+
+func (h *HealthController) checkStorage(ctx context.Context) ComponentHealth {
+	log := loggerFrom(ctx, h.log)
+
+	file, err := os.CreateTemp(config.Get().Server.StorageDir, ".readyz-*")
+
+	if err != nil {
+		log.Error("Storage directory is not writable", logger.ErrorKey, err)
+		return ComponentHealth{Status: HealthStatusFail, Message: "storage directory is not writable"}
+	}
+
+	name := file.Name()
+	_, err = file.WriteString("ok")
+	file.Close()
+	os.Remove(name)
+
+	if err != nil {
+		log.Error("Storage directory is not writable", logger.ErrorKey, err)
+		return ComponentHealth{Status: HealthStatusFail, Message: "storage directory is not writable"}
+	}
+
+	return ComponentHealth{Status: HealthStatusOK}
+}
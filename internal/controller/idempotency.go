@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/models"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// IdempotencyController stores the responses of requests sent with an Idempotency-Key.
+type IdempotencyController struct {
+	keys  repository.IdempotencyRepository
+	clock clock.Clock
+	log   *slog.Logger
+}
+
+func NewIdempotencyController(keys repository.IdempotencyRepository, clk clock.Clock, log *slog.Logger) *IdempotencyController {
+	return &IdempotencyController{keys: keys, clock: clk, log: log}
+}
+
+// ReserveIdempotencyKey claims the key for a request. It returns nil when the
+// request should be handled, or the stored record when the same request was
+// already completed and its response must be replayed.
+func (i *IdempotencyController) ReserveIdempotencyKey(ctx context.Context, key string, scope string, requestHash string, ttl time.Duration) (*models.IdempotencyKey, error) {
+	ctx, span := tracing.Start(ctx, "controller.ReserveIdempotencyKey")
+	defer span.End()
+
+	log := loggerFrom(ctx, i.log)
+
+	now := i.clock.Now()
+
+	reservation := models.IdempotencyKey{
+		Key:         key,
+		Scope:       scope,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	reserved, err := i.keys.Reserve(ctx, &reservation)
+
+	if err != nil {
+		log.Error("Failed to reserve idempotency key", "scope", scope, logger.ErrorKey, err)
+		return nil, apperr.Internal(err, "failed to check idempotency key")
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	stored, err := i.keys.Find(ctx, key, scope)
+
+	if err != nil {
+		log.Error("Failed to lookup idempotency key", "scope", scope, logger.ErrorKey, err)
+		return nil, apperr.Internal(err, "failed to check idempotency key")
+	}
+
+	if stored.ExpiresAt.Before(now) {
+		// The periodic cleanup hasn't removed the key yet, it is free again.
+		if err := i.keys.DeleteExpired(ctx, now); err != nil {
+			log.Error("Failed to delete expired idempotency keys", logger.ErrorKey, err)
+			return nil, apperr.Internal(err, "failed to check idempotency key")
+		}
+
+		return i.ReserveIdempotencyKey(ctx, key, scope, requestHash, ttl)
+	}
+
+	if stored.RequestHash != requestHash {
+		log.Info("Idempotency key reused with another payload", "scope", scope)
+		return nil, apperr.Validation(apperr.CodeIdempotencyKeyReused, "Idempotency-Key was already used for a different request")
+	}
+
+	if !stored.Completed {
+		log.Info("Request with the same idempotency key is still in progress", "scope", scope)
+		return nil, apperr.Conflict(apperr.CodeIdempotencyInProgress, "a request with this Idempotency-Key is still being processed")
+	}
+
+	return &stored, nil
+}
+
+// DeleteExpiredIdempotencyKeys removes every key past its TTL. The server runs
+// it periodically, see IDEMPOTENCY_CLEANUP_INTERVAL.
+func (i *IdempotencyController) DeleteExpiredIdempotencyKeys(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "controller.DeleteExpiredIdempotencyKeys")
+	defer span.End()
+
+	if err := i.keys.DeleteExpired(ctx, i.clock.Now()); err != nil {
+		loggerFrom(ctx, i.log).Error("Failed to delete expired idempotency keys", logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to delete expired idempotency keys")
+	}
+
+	return nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved the key.
+func (i *IdempotencyController) CompleteIdempotencyKey(ctx context.Context, key string, scope string, status int, header http.Header, body []byte) error {
+	ctx, span := tracing.Start(ctx, "controller.CompleteIdempotencyKey")
+	defer span.End()
+
+	log := loggerFrom(ctx, i.log)
+
+	encodedHeader, err := json.Marshal(header)
+
+	if err != nil {
+		log.Error("Failed to encode response header for idempotency key", logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to store idempotent response")
+	}
+
+	if err := i.keys.Complete(ctx, key, scope, status, string(encodedHeader), body); err != nil {
+		log.Error("Failed to store idempotent response", "scope", scope, logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to store idempotent response")
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey forgets a reservation, so the request can be retried
+// with the same key after a failure that wasn't the client's fault.
+func (i *IdempotencyController) ReleaseIdempotencyKey(ctx context.Context, key string, scope string) error {
+	ctx, span := tracing.Start(ctx, "controller.ReleaseIdempotencyKey")
+	defer span.End()
+
+	log := loggerFrom(ctx, i.log)
+
+	if err := i.keys.Release(ctx, key, scope); err != nil {
+		log.Error("Failed to release idempotency key", "scope", scope, logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to release idempotency key")
+	}
+
+	return nil
+}
+
+// IdempotentResponseHeader decodes the header stored with a completed key.
+func (i *IdempotencyController) IdempotentResponseHeader(ctx context.Context, stored *models.IdempotencyKey) http.Header {
+	header := http.Header{}
+
+	if stored.Header != "" {
+		if err := json.Unmarshal([]byte(stored.Header), &header); err != nil {
+			loggerFrom(ctx, i.log).Warn("Stored idempotent response header is invalid", logger.ErrorKey, err)
+		}
+	}
+
+	return header
+}
@@ -1,16 +1,17 @@
 package controller
 
 import (
-	"errors"
-	"fmt"
+	"context"
 	"strings"
 	"time"
 
+	"himatro-api/internal/apperr"
 	"himatro-api/internal/config"
 	"himatro-api/internal/contract"
-	"himatro-api/internal/db"
+	"himatro-api/internal/logger"
 	"himatro-api/internal/models"
-	"himatro-api/internal/util"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
 )
 
 type InitAbsentData struct {
@@ -20,45 +21,55 @@ type InitAbsentData struct {
 	FinishAt                    time.Time `json:"finishAt" validate:"required"`
 	RequireAttendanceImageProof bool      `json:"requireAttendanceImageProof" validate:"required"`
 	RequireExecuseImageProof    bool      `json:"requireExecuseImageProof" validate:"required"`
+	ResultVisibility            string    `json:"resultVisibility"`
+	TimeZone                    string    `json:"timeZone"`
 }
 
-func ExtractInitAbsentPayload(payload contract.CreateAbsentForm) (InitAbsentData, error) {
-	start, err := parseDate(payload.StartAtDate, payload.StartAtTime)
+func (a *AbsentController) ExtractInitAbsentPayload(ctx context.Context, payload contract.CreateAbsentForm) (InitAbsentData, error) {
+	ctx, span := tracing.Start(ctx, "controller.ExtractInitAbsentPayload")
+	defer span.End()
 
-	if err != nil {
-		util.LogErr("WARN", "field start time and date is invalid", err.Error())
-		return InitAbsentData{}, errors.New("field start time and date is invalid")
-	}
+	log := loggerFrom(ctx, a.log)
+
+	timeZone := formTimeZone(payload.TimeZone)
+	loc := formLocation(timeZone)
 
-	end, err := parseDate(payload.FinishAtDate, payload.FinishAtTime)
+	start, err := a.parseFormTime(ctx, payload.StartAt, payload.StartAtDate, payload.StartAtTime, loc)
 
 	if err != nil {
-		util.LogErr("WARN", "field finish time and date is invalid", err.Error())
-		return InitAbsentData{}, errors.New("field finish time and date is invalid")
+		log.Info("Field start time and date is invalid", logger.ErrorKey, err)
+		return InitAbsentData{}, apperr.Validation(apperr.CodeInvalidDate, "field start time and date is invalid: %s", err.Error())
 	}
 
-	if start.After(end) {
-		util.LogErr("WARN", "start date must happen before finish date", err.Error())
-		return InitAbsentData{}, errors.New("start date must happen before finish date")
+	end, err := a.parseFormTime(ctx, payload.FinishAt, payload.FinishAtDate, payload.FinishAtTime, loc)
+
+	if err != nil {
+		log.Info("Field finish time and date is invalid", logger.ErrorKey, err)
+		return InitAbsentData{}, apperr.Validation(apperr.CodeInvalidDate, "field finish time and date is invalid: %s", err.Error())
 	}
 
-	if time.Now().After(end) {
-		util.LogErr("WARN", "absent form can't finish before current date", err.Error())
-		return InitAbsentData{}, errors.New("absent form can't finish before current date")
+	if err := a.validateSchedule(ctx, start, end); err != nil {
+		return InitAbsentData{}, err
 	}
 
-	if start.String() == end.String() {
-		util.LogErr("WARN", "form absent cant't start and end in the same time", err.Error())
-		return InitAbsentData{}, errors.New("form absent cant't start and end in the same time")
+	if a.clock.Now().After(end) {
+		log.Info("Absent form can't finish before current date", "finishAt", end)
+		return InitAbsentData{}, apperr.Validation(apperr.CodeInvalidSchedule, "absent form can't finish before current date")
 	}
 
-	participantCode, err := validateParticipantCode(payload.Participant)
+	participantCode, err := a.validateParticipantCode(ctx, payload.Participant)
 
 	if err != nil {
-		util.LogErr("WARN", fmt.Sprintf("Invalid participant code used: %s", payload.Participant), err.Error())
+		log.Info("Invalid participant code used", "participant", payload.Participant, logger.ErrorKey, err)
 		return InitAbsentData{}, err
 	}
 
+	resultVisibility := payload.ResultVisibility
+
+	if resultVisibility == "" {
+		resultVisibility = models.ResultVisibilityPublic
+	}
+
 	initAbsentData := InitAbsentData{
 		Title:                       payload.Title,
 		Participant:                 participantCode,
@@ -66,12 +77,21 @@ func ExtractInitAbsentPayload(payload contract.CreateAbsentForm) (InitAbsentData
 		FinishAt:                    end,
 		RequireAttendanceImageProof: payload.RequireAttendanceImageProof,
 		RequireExecuseImageProof:    payload.RequireExecuseImageProof,
+		ResultVisibility:            resultVisibility,
+		TimeZone:                    timeZone,
 	}
 
 	return initAbsentData, nil
 }
 
-func RegisterNewAbsentForm(detail *InitAbsentData) (uint, error) {
+// RegisterNewAbsentForm creates the form and its absent list in one transaction,
+// so a failure never leaves a form without participants behind.
+func (a *AbsentController) RegisterNewAbsentForm(ctx context.Context, detail *InitAbsentData) (uint, error) {
+	ctx, span := tracing.Start(ctx, "controller.RegisterNewAbsentForm")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
 	newAbsent := models.FormAbsensi{
 		Title:                       detail.Title,
 		Participant:                 detail.Participant,
@@ -79,61 +99,105 @@ func RegisterNewAbsentForm(detail *InitAbsentData) (uint, error) {
 		FinishAt:                    detail.FinishAt,
 		RequireAttendanceImageProof: detail.RequireAttendanceImageProof,
 		RequireExecuseImageProof:    detail.RequireExecuseImageProof,
+		ResultVisibility:            detail.ResultVisibility,
+		TimeZone:                    detail.TimeZone,
 	}
 
-	err := db.DB.Create(&newAbsent)
+	err := a.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Forms().Create(ctx, &newAbsent); err != nil {
+			log.Error("System failed to register new absent form", "title", newAbsent.Title, logger.ErrorKey, err)
+			return apperr.Internal(err, "system failed to register new absent")
+		}
 
-	if err.Error != nil {
-		util.LogErr("ERROR", fmt.Sprintf("system failed to register new absent for %s", newAbsent.Title), err.Error.Error())
-		return 0, errors.New("system failed to register new absent")
+		return a.createNewAbsentList(ctx, tx, int(newAbsent.ID), detail.Participant)
+	})
+
+	if err != nil {
+		log.Error("Failed to create absent form, changes are rolled back", "title", newAbsent.Title, logger.ErrorKey, err)
+		return 0, err
 	}
 
 	return newAbsent.ID, nil
 }
 
-func InitAbsentList(detail *InitAbsentData, absentID uint) error {
-	listPengurus, err := getAllNPMFromDepartemenID(detail.Participant)
+// formDateTimeLayout reads a date and a wall clock time. Single digit months,
+// days, hours, minutes and seconds are accepted, as they always were.
+const formDateTimeLayout = "2006-1-2 15:4:5"
 
-	if err != nil {
-		util.LogErr("ERROR", fmt.Sprintf("failed to generate absent lists for: %d", absentID), err.Error())
-		return errors.New("failed to generate absent lists")
-	}
+// parseFormTime reads a schedule time from a date and a wall clock time in loc
+// when either is set, otherwise from the RFC 3339 timestamp at, which carries
+// its own offset. The date is checked against the calendar, so 2024-02-30 is
+// rejected. The result is in loc.
+func (a *AbsentController) parseFormTime(ctx context.Context, at string, date string, clock string, loc *time.Location) (time.Time, error) {
+	log := loggerFrom(ctx, a.log)
 
-	absentLists := generateAbsentList(listPengurus, absentID)
+	if date == "" && clock == "" {
+		parsed, err := time.Parse(time.RFC3339, at)
 
-	errs := db.DB.Create(&absentLists)
+		if err != nil {
+			log.Debug("Invalid timestamp received", "value", at, logger.ErrorKey, err)
+			return time.Time{}, err
+		}
 
-	if errs.Error != nil {
-		util.LogErr("ERROR", fmt.Sprintf("failed to generate absent lists for: %d", absentID), err.Error())
-		return errors.New("failed to generate absent lists")
+		return parsed.In(loc), nil
 	}
 
-	return nil
-}
+	if strings.Count(clock, ":") == 1 {
+		clock += ":00" // if user send only hour and minute
+	}
 
-func parseDate(startAtDate string, startAtTime string) (time.Time, error) {
-	year, month, day, err := util.ExtractDateString(startAtDate)
+	parsed, err := time.ParseInLocation(formDateTimeLayout, date+" "+clock, loc)
 
 	if err != nil {
-		util.LogErr("WARN", fmt.Sprintf("invalid start date string received: %s", startAtDate), err.Error())
-		return time.Now(), err
+		log.Debug("Invalid date time string received", "date", date, "time", clock, logger.ErrorKey, err)
+		return time.Time{}, err
+	}
+
+	return parsed, nil
+}
+
+// formTimeZone is the zone a form is entered in: the requested one, or the
+// server's TZ when none is requested.
+func formTimeZone(timeZone string) string {
+	if timeZone == "" {
+		return config.Get().Server.TimeZone
 	}
 
-	hour, minute, sec, err := util.ExtractTimeString(startAtTime)
+	return timeZone
+}
+
+// formLocation loads the zone of a form. The zone is validated before it is
+// stored, so UTC is only used when the server lost its zone database.
+func formLocation(timeZone string) *time.Location {
+	loc, err := time.LoadLocation(formTimeZone(timeZone))
 
 	if err != nil {
-		util.LogErr("WARN", fmt.Sprintf("invalid start time string received: %s", startAtTime), err.Error())
-		return time.Now(), err
+		return time.UTC
 	}
 
-	date := time.Date(year, time.Month(month), day, hour, minute, sec, 0, time.Local)
+	return loc
+}
+
+// validateSchedule checks that a form opens strictly before it closes.
+func (a *AbsentController) validateSchedule(ctx context.Context, start time.Time, end time.Time) error {
+	log := loggerFrom(ctx, a.log)
+
+	if start.After(end) {
+		log.Info("Start date must happen before finish date", "startAt", start, "finishAt", end)
+		return apperr.Validation(apperr.CodeInvalidSchedule, "start date must happen before finish date")
+	}
 
-	tz, _ := time.LoadLocation(config.TimeZone())
+	if start.Equal(end) {
+		log.Info("Form absent can't start and end in the same time", "startAt", start)
+		return apperr.Validation(apperr.CodeInvalidSchedule, "form absent cant't start and end in the same time")
+	}
 
-	return date.In(tz), nil
+	return nil
 }
 
-func validateParticipantCode(participant string) (int, error) {
+func (a *AbsentController) validateParticipantCode(ctx context.Context, participant string) (int, error) {
+	log := loggerFrom(ctx, a.log)
+
 	switch strings.ToUpper(participant) {
 	case "PH":
 		return 1, nil
@@ -150,19 +214,39 @@ func validateParticipantCode(participant string) (int, error) {
 	case "ALL":
 		return 0, nil // create absent for all
 	default:
-		util.LogErr("WARN", fmt.Sprintf("participant (departemenID) is invalid: %s", participant), "")
-		return 0, errors.New("participant (departemenID) is invalid")
+		log.Debug("Participant (departemenID) is invalid", "participant", participant)
+		return 0, apperr.Validation(apperr.CodeInvalidPartCode, "participant (departemenID) is invalid")
 	}
 }
 
-func getAllNPMFromDepartemenID(departemenID int) ([]models.Pengurus, error) {
-	pengurus := []models.Pengurus{}
+// participantName is the reverse of validateParticipantCode.
+func participantName(participantCode int) string {
+	switch participantCode {
+	case 1:
+		return "PH"
+	case 2:
+		return "PPD"
+	case 3:
+		return "KPO"
+	case 4:
+		return "KOMINFO"
+	case 5:
+		return "KWU"
+	case 6:
+		return "BANGTEK"
+	default:
+		return "ALL"
+	}
+}
 
-	err := db.DB.Where(&models.Pengurus{DepartemenID: departemenID}).Find(&pengurus) // if departemenID 0, query all see: https://gorm.io/docs/query.html#Struct-amp-Map-Conditions
+func (a *AbsentController) getAllNPMFromDepartemenID(ctx context.Context, tx repository.Store, departemenID int) ([]models.Pengurus, error) {
+	log := loggerFrom(ctx, a.log)
 
-	if err.Error != nil {
-		util.LogErr("ERROR", fmt.Sprintf("failed to instaniate new absent list departemenID: %d", departemenID), err.Error.Error())
-		return pengurus, errors.New("failed to instaniate new absent list")
+	pengurus, err := tx.Members().ListPengurus(ctx, departemenID)
+
+	if err != nil {
+		log.Error("Failed to instantiate new absent list", "departemenID", departemenID, logger.ErrorKey, err)
+		return pengurus, apperr.Internal(err, "failed to instaniate new absent list")
 	}
 
 	return pengurus, nil
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"context"
+	"himatro-api/internal/logger"
+	"log/slog"
+)
+
+// loggerFrom returns the request scoped logger stored in ctx, so every line
+// logged while handling a request carries its request ID. Outside of a request,
+// e.g. in a command, it returns fallback, the logger the controller was
+// constructed with.
+func loggerFrom(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if l, ok := logger.FromContext(ctx); ok {
+		return l
+	}
+
+	return fallback
+}
@@ -1,67 +1,98 @@
 package controller
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"himatro-api/internal/apperr"
 	"himatro-api/internal/auth"
+	"himatro-api/internal/clock"
 	"himatro-api/internal/contract"
-	"himatro-api/internal/db"
-	"himatro-api/internal/models"
-	"himatro-api/internal/util"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
+	"log/slog"
 
 	"github.com/labstack/echo/v4"
 )
 
-func GetUserPassword(NPM string) (string, error) {
-	user := models.User{}
+// AuthController checks admin credentials and issues login tokens.
+type AuthController struct {
+	users repository.UserRepository
+	clock clock.Clock
+	log   *slog.Logger
+}
+
+func NewAuthController(users repository.UserRepository, clk clock.Clock, log *slog.Logger) *AuthController {
+	return &AuthController{users: users, clock: clk, log: log}
+}
+
+func (a *AuthController) GetUserPassword(ctx context.Context, NPM string) (string, error) {
+	ctx, span := tracing.Start(ctx, "controller.GetUserPassword")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	user, err := a.users.FindByNPM(ctx, NPM)
 
-	err := db.DB.Where("npm = ?", NPM).First(&user)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Login rejected, unknown NPM", logger.NPMKey, NPM)
+		return "", apperr.Unauthorized(apperr.CodeInvalidCredentials, "login credentials in not valid")
+	}
 
-	if err.Error != nil {
-		util.LogErr("WARN", "Invalid login credentials were used", NPM)
-		return "", errors.New("login credentials in not valid")
+	if err != nil {
+		log.Error("Failed to find user for login", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return "", apperr.Internal(err, "system failure to check login credentials")
 	}
 
 	return user.Password, nil
 }
 
-func ExtractLoginPayload(c echo.Context) (string, string, error) {
+func (a *AuthController) ExtractLoginPayload(c echo.Context) (string, string, error) {
+	log := loggerFrom(c.Request().Context(), a.log)
+
 	payload := new(contract.LoginPayload)
 
 	if err := c.Bind(payload); err != nil {
-		util.LogErr("WARN", "Invalid login payload were used", err.Error())
-		return "", "", errors.New("payload is incorrect")
+		log.Info("Invalid login payload were used", logger.ErrorKey, err)
+		return "", "", apperr.BadRequest(apperr.CodeInvalidPayload, "payload is incorrect")
 	}
 
 	if payload.NPM == "" || payload.Password == "" {
-		return "", "", errors.New("NPM and password must be supplied")
+		return "", "", apperr.Validation(apperr.CodeValidationFailed, "NPM and password must be supplied")
 	}
 
 	return payload.NPM, payload.Password, nil
 }
 
-func ValidatePassword(plain string, encrypted string) error {
+func (a *AuthController) ValidatePassword(ctx context.Context, plain string, encrypted string) error {
+	ctx, span := tracing.Start(ctx, "controller.ValidatePassword")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
 	decrypted, err := auth.Decrypt(encrypted)
 
 	if err != nil {
-		util.LogErr("WARN", "Decryption process failed", err.Error())
-		return errors.New("credentials invalid")
+		log.Error("Decryption process of stored password failed", logger.ErrorKey, err)
+		return apperr.Internal(err, "credentials invalid")
 	}
 
 	if decrypted != plain {
-		util.LogErr("WARN", "Invalid login credentials were used", "")
-		return errors.New("credentials invalid")
+		log.Info("Login rejected, wrong password")
+		return apperr.Unauthorized(apperr.CodeInvalidCredentials, "credentials invalid")
 	}
 
 	return nil
 }
 
-func CreateLoginToken(c echo.Context, NPM string) (string, error) {
-	loginToken, err := auth.CreateLoginToken(NPM)
+func (a *AuthController) CreateLoginToken(c echo.Context, NPM string) (string, error) {
+	log := loggerFrom(c.Request().Context(), a.log)
+
+	loginToken, err := auth.CreateLoginToken(a.clock, NPM)
 
 	if err != nil {
-		util.LogErr("ERROR", fmt.Sprintf("Failed to create login token for NPM: %s", NPM), err.Error())
-		return "", err
+		log.Error("Failed to create login token", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return "", apperr.Internal(err, "failed to create login token")
 	}
 
 	return loginToken, nil
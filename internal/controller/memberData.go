@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/models"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
+	"log/slog"
+)
+
+const anonymizedMemberName = "Anonim"
+
+// MemberController exports and erases the personal data of members.
+type MemberController struct {
+	store repository.Store
+	clock clock.Clock
+	log   *slog.Logger
+}
+
+func NewMemberController(store repository.Store, clk clock.Clock, log *slog.Logger) *MemberController {
+	return &MemberController{store: store, clock: clk, log: log}
+}
+
+// ExportMemberData collects every record linked to the given NPM, including the
+// absent lists of archived forms, which are still stored until purged.
+func (m *MemberController) ExportMemberData(ctx context.Context, NPM string) (models.ReturnedMemberData, error) {
+	ctx, span := tracing.Start(ctx, "controller.ExportMemberData")
+	defer span.End()
+
+	log := loggerFrom(ctx, m.log)
+
+	anggotaBiasa, err := m.store.Members().FindAnggota(ctx, NPM)
+
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Member not found", logger.NPMKey, NPM)
+		return models.ReturnedMemberData{}, apperr.NotFound(apperr.CodeMemberNotFound, "member with NPM: %s is not found", NPM)
+	}
+
+	if err != nil {
+		log.Error("Failed to lookup member", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return models.ReturnedMemberData{}, apperr.Internal(err, "system failure to lookup member")
+	}
+
+	memberData := models.ReturnedMemberData{
+		ExportedAt:    m.clock.Now(),
+		AnggotaBiasa:  anggotaBiasa,
+		AbsentHistory: []models.ReturnedMemberAbsentHistory{},
+	}
+
+	pengurus, err := m.store.Members().FindPengurus(ctx, NPM)
+
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Error("Failed to export pengurus data", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return models.ReturnedMemberData{}, apperr.Internal(err, "failed to export member data")
+	}
+
+	if err == nil {
+		memberData.Pengurus = &models.ReturnedMemberPengurus{
+			DepartemenID: pengurus.DepartemenID,
+			JabatanID:    pengurus.JabatanID,
+		}
+	}
+
+	user, err := m.store.Users().FindByNPM(ctx, NPM)
+
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Error("Failed to export user data", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return models.ReturnedMemberData{}, apperr.Internal(err, "failed to export member data")
+	}
+
+	if err == nil {
+		memberData.User = &models.ReturnedMemberUser{
+			NPM:       user.NPM,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		}
+	}
+
+	memberData.AbsentHistory, err = m.store.AbsentLists().ListHistory(ctx, NPM)
+
+	if err != nil {
+		log.Error("Failed to export absent history", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return models.ReturnedMemberData{}, apperr.Internal(err, "failed to export member data")
+	}
+
+	return memberData, nil
+}
+
+// ZipMemberData bundles the exported member data as a single JSON file inside a ZIP archive.
+func (m *MemberController) ZipMemberData(ctx context.Context, memberData models.ReturnedMemberData) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "controller.ZipMemberData")
+	defer span.End()
+
+	log := loggerFrom(ctx, m.log)
+
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+
+	file, err := archive.Create(fmt.Sprintf("member_%s.json", memberData.AnggotaBiasa.NPM))
+
+	if err != nil {
+		log.Error("Failed to create member data archive", logger.ErrorKey, err)
+		return nil, apperr.Internal(err, "failed to create member data archive")
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(memberData); err != nil {
+		log.Error("Failed to write member data archive", logger.ErrorKey, err)
+		return nil, apperr.Internal(err, "failed to create member data archive")
+	}
+
+	if err := archive.Close(); err != nil {
+		log.Error("Failed to close member data archive", logger.ErrorKey, err)
+		return nil, apperr.Internal(err, "failed to create member data archive")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EraseMemberData anonymizes a member. The absent lists move to a random
+// pseudonym, so they keep their rows and aggregate attendance counts stay
+// correct. The pengurus record and admin credentials for the NPM are removed, so
+// the pseudonym is no participant of forms created later.
+// The pseudonym is never returned or logged, so nothing links it back to the NPM.
+func (m *MemberController) EraseMemberData(ctx context.Context, NPM string) error {
+	ctx, span := tracing.Start(ctx, "controller.EraseMemberData")
+	defer span.End()
+
+	log := loggerFrom(ctx, m.log)
+
+	_, err := m.store.Members().FindAnggota(ctx, NPM)
+
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Member not found", logger.NPMKey, NPM)
+		return apperr.NotFound(apperr.CodeMemberNotFound, "member with NPM: %s is not found", NPM)
+	}
+
+	if err != nil {
+		log.Error("Failed to lookup member", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return apperr.Internal(err, "system failure to lookup member")
+	}
+
+	pseudonym, err := generatePseudonymNPM()
+
+	if err != nil {
+		log.Error("Failed to generate pseudonym for member erasure", logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to erase member data")
+	}
+
+	err = m.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Members().CreateAnggota(ctx, &models.AnggotaBiasa{NPM: pseudonym, Nama: anonymizedMemberName}); err != nil {
+			return err
+		}
+
+		if err := tx.Members().DeletePengurus(ctx, NPM); err != nil {
+			return err
+		}
+
+		if err := tx.AbsentLists().ReplaceNPM(ctx, NPM, pseudonym); err != nil {
+			return err
+		}
+
+		if err := tx.Users().DeleteByNPM(ctx, NPM); err != nil {
+			return err
+		}
+
+		return tx.Members().DeleteAnggota(ctx, NPM)
+	})
+
+	if err != nil {
+		log.Error("Failed to erase member data", logger.NPMKey, NPM, logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to erase member data")
+	}
+
+	log.Info("Member data erased", logger.NPMKey, NPM)
+
+	return nil
+}
+
+func generatePseudonymNPM() (string, error) {
+	b := make([]byte, 6)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "ANON-" + hex.EncodeToString(b), nil
+}
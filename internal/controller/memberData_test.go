@@ -0,0 +1,69 @@
+package controller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"himatro-api/internal/clock"
+	"himatro-api/internal/controller"
+	"himatro-api/internal/models"
+	"himatro-api/internal/repository"
+)
+
+func TestErasedMemberIsNoParticipantOfNewForms(t *testing.T) {
+	tests := []struct {
+		name        string
+		participant int
+	}{
+		{"departemen form", 4},
+		{"form for every pengurus", 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clk := clock.NewFake(opening.Add(-time.Hour))
+			store := repository.NewMemoryStore(clk)
+
+			store.AddDepartemen(models.Departemen{ID: 4, Nama: "Kominfo"})
+
+			for _, NPM := range []string{"2115061001", "2115061002"} {
+				if err := store.Members().CreateAnggota(ctx, &models.AnggotaBiasa{NPM: NPM, Nama: "Andi"}); err != nil {
+					t.Fatalf("create anggota: %v", err)
+				}
+
+				if err := store.Members().CreatePengurus(ctx, &models.Pengurus{NPM: NPM, DepartemenID: 4, JabatanID: 1}); err != nil {
+					t.Fatalf("create pengurus: %v", err)
+				}
+			}
+
+			if err := controller.NewMemberController(store, clk, discard).EraseMemberData(ctx, "2115061001"); err != nil {
+				t.Fatalf("erase: %v", err)
+			}
+
+			formID, err := controller.NewAbsentController(store, clk, discard).RegisterNewAbsentForm(ctx, &controller.InitAbsentData{
+				Title:       "Rapat",
+				Participant: tt.participant,
+				StartAt:     opening,
+				FinishAt:    opening.Add(time.Hour),
+			})
+
+			if err != nil {
+				t.Fatalf("register form: %v", err)
+			}
+
+			absentLists, err := store.AbsentLists().ListByForm(ctx, formID)
+
+			if err != nil {
+				t.Fatalf("list participants: %v", err)
+			}
+
+			if len(absentLists) != 1 || absentLists[0].NPM != "2115061002" {
+				t.Errorf("participants %+v, want only 2115061002", absentLists)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/contract"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/models"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
+	"himatro-api/internal/util"
+	"time"
+)
+
+// PatchAbsentForm applies a JSON merge patch to the form. The patch document has
+// the same fields as the create payload, with the schedule as RFC 3339 startAt
+// and finishAt. Patching timeZone alone keeps the schedule and only changes the
+// zone it is shown in. The whole resulting form is validated
+// before anything is written, and all changes are saved in one transaction.
+// A non zero version must match the current version of the form.
+func (a *AbsentController) PatchAbsentForm(ctx context.Context, formID int, patch []byte, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.PatchAbsentForm")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	patched := models.FormAbsensi{}
+
+	err := a.store.Transaction(ctx, func(tx repository.Store) error {
+		formAbsent, err := a.findFormAbsent(ctx, tx, formID)
+
+		if err != nil {
+			return err
+		}
+
+		if err := a.checkFormVersion(ctx, formAbsent, version); err != nil {
+			return err
+		}
+
+		payload, err := a.mergeAbsentFormPatch(ctx, formAbsent, patch)
+
+		if err != nil {
+			return err
+		}
+
+		detail, err := a.extractPatchedForm(ctx, payload)
+
+		if err != nil {
+			return err
+		}
+
+		if !detail.FinishAt.Equal(formAbsent.FinishAt) && a.clock.Now().After(detail.FinishAt) {
+			log.Info("Absent form can't finish before current date", logger.FormIDKey, formID, "finishAt", detail.FinishAt)
+			return apperr.Validation(apperr.CodeInvalidSchedule, "absent form can't finish before current date")
+		}
+
+		if detail.Participant != formAbsent.Participant {
+			if err := a.isParticipantChangeable(ctx, tx, formID); err != nil {
+				return err
+			}
+
+			if err := a.deleteOldAbsentList(ctx, tx, formID); err != nil {
+				return err
+			}
+
+			if err := a.createNewAbsentList(ctx, tx, formID, detail.Participant); err != nil {
+				return err
+			}
+		}
+
+		formAbsent.Title = detail.Title
+		formAbsent.Participant = detail.Participant
+		formAbsent.StartAt = detail.StartAt
+		formAbsent.FinishAt = detail.FinishAt
+		formAbsent.RequireAttendanceImageProof = detail.RequireAttendanceImageProof
+		formAbsent.RequireExecuseImageProof = detail.RequireExecuseImageProof
+		formAbsent.ResultVisibility = detail.ResultVisibility
+		formAbsent.TimeZone = detail.TimeZone
+
+		if err := a.saveAbsentForm(ctx, tx, &formAbsent); err != nil {
+			return err
+		}
+
+		patched = formAbsent
+
+		return nil
+	})
+
+	if err != nil {
+		log.Info("Failed to patch absent form", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	return absentFormRepresentation(patched), nil
+}
+
+// mergeAbsentFormPatch applies the patch to the form written as a create payload.
+func (a *AbsentController) mergeAbsentFormPatch(ctx context.Context, formAbsent models.FormAbsensi, patch []byte) (contract.CreateAbsentForm, error) {
+	log := loggerFrom(ctx, a.log)
+
+	current, err := json.Marshal(absentFormDocument(formAbsent))
+
+	if err != nil {
+		return contract.CreateAbsentForm{}, apperr.Internal(err, "server failure to update form details")
+	}
+
+	merged, err := util.MergePatch(current, patch)
+
+	if err != nil {
+		log.Debug("Invalid merge patch received", logger.FormIDKey, formAbsent.ID, logger.ErrorKey, err)
+		return contract.CreateAbsentForm{}, apperr.BadRequest(apperr.CodeInvalidPayload, "merge patch is not a valid JSON document")
+	}
+
+	payload := contract.CreateAbsentForm{}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&payload); err != nil {
+		log.Debug("Merge patch does not fit an absent form", logger.FormIDKey, formAbsent.ID, logger.ErrorKey, err)
+		return contract.CreateAbsentForm{}, apperr.BadRequest(apperr.CodeInvalidPayload, "merge patch does not fit an absent form: %s", err.Error())
+	}
+
+	if err := util.Validator.Struct(&payload); err != nil {
+		return contract.CreateAbsentForm{}, apperr.Validation(apperr.CodeValidationFailed, "JSON payload validation error").
+			WithDetails(util.ExtractValidationErrorMsg(err))
+	}
+
+	return payload, nil
+}
+
+func (a *AbsentController) extractPatchedForm(ctx context.Context, payload contract.CreateAbsentForm) (InitAbsentData, error) {
+	log := loggerFrom(ctx, a.log)
+
+	timeZone := formTimeZone(payload.TimeZone)
+	loc := formLocation(timeZone)
+
+	start, err := a.parseFormTime(ctx, payload.StartAt, payload.StartAtDate, payload.StartAtTime, loc)
+
+	if err != nil {
+		log.Info("Field start time and date is invalid", logger.ErrorKey, err)
+		return InitAbsentData{}, apperr.Validation(apperr.CodeInvalidDate, "field start time and date is invalid: %s", err.Error())
+	}
+
+	end, err := a.parseFormTime(ctx, payload.FinishAt, payload.FinishAtDate, payload.FinishAtTime, loc)
+
+	if err != nil {
+		log.Info("Field finish time and date is invalid", logger.ErrorKey, err)
+		return InitAbsentData{}, apperr.Validation(apperr.CodeInvalidDate, "field finish time and date is invalid: %s", err.Error())
+	}
+
+	if err := a.validateSchedule(ctx, start, end); err != nil {
+		return InitAbsentData{}, err
+	}
+
+	participantCode, err := a.validateParticipantCode(ctx, payload.Participant)
+
+	if err != nil {
+		return InitAbsentData{}, err
+	}
+
+	resultVisibility := payload.ResultVisibility
+
+	if resultVisibility == "" {
+		resultVisibility = models.ResultVisibilityPublic
+	}
+
+	return InitAbsentData{
+		Title:                       payload.Title,
+		Participant:                 participantCode,
+		StartAt:                     start,
+		FinishAt:                    end,
+		RequireAttendanceImageProof: payload.RequireAttendanceImageProof,
+		RequireExecuseImageProof:    payload.RequireExecuseImageProof,
+		ResultVisibility:            resultVisibility,
+		TimeZone:                    timeZone,
+	}, nil
+}
+
+// absentFormDocument writes the form in the shape of the create payload, which is
+// the document a merge patch is applied to. The schedule is written as RFC 3339
+// timestamps in the form's zone, the date and time fields are left out so that
+// a patch may set either.
+func absentFormDocument(formAbsent models.FormAbsensi) contract.CreateAbsentForm {
+	loc := formLocation(formAbsent.TimeZone)
+
+	resultVisibility := formAbsent.ResultVisibility
+
+	if resultVisibility == "" {
+		resultVisibility = models.ResultVisibilityPublic
+	}
+
+	return contract.CreateAbsentForm{
+		Title:                       formAbsent.Title,
+		StartAt:                     formAbsent.StartAt.In(loc).Format(time.RFC3339),
+		FinishAt:                    formAbsent.FinishAt.In(loc).Format(time.RFC3339),
+		TimeZone:                    formTimeZone(formAbsent.TimeZone),
+		RequireAttendanceImageProof: formAbsent.RequireAttendanceImageProof,
+		RequireExecuseImageProof:    formAbsent.RequireExecuseImageProof,
+		Participant:                 participantName(formAbsent.Participant),
+		ResultVisibility:            resultVisibility,
+	}
+}
+
+// absentFormRepresentation shows the schedule in the form's zone, so every time
+// carries that zone's offset.
+func absentFormRepresentation(formAbsent models.FormAbsensi) models.ReturnedAbsentForm {
+	loc := formLocation(formAbsent.TimeZone)
+
+	resultVisibility := formAbsent.ResultVisibility
+
+	if resultVisibility == "" {
+		resultVisibility = models.ResultVisibilityPublic
+	}
+
+	var archivedAt *time.Time
+
+	if formAbsent.DeletedAt.Valid {
+		archivedAt = &formAbsent.DeletedAt.Time
+	}
+
+	return models.ReturnedAbsentForm{
+		FormID:                      formAbsent.ID,
+		Title:                       formAbsent.Title,
+		Participant:                 participantName(formAbsent.Participant),
+		StartAt:                     formAbsent.StartAt.In(loc),
+		FinishAt:                    formAbsent.FinishAt.In(loc),
+		RequireAttendanceImageProof: formAbsent.RequireAttendanceImageProof,
+		RequireExecuseImageProof:    formAbsent.RequireExecuseImageProof,
+		ResultVisibility:            resultVisibility,
+		TimeZone:                    formTimeZone(formAbsent.TimeZone),
+		ArchivedAt:                  archivedAt,
+		Version:                     formAbsent.Version,
+		CreatedAt:                   formAbsent.CreatedAt,
+		UpdatedAt:                   formAbsent.UpdatedAt,
+	}
+}
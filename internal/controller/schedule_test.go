@@ -0,0 +1,187 @@
+package controller_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/config"
+	"himatro-api/internal/contract"
+	"himatro-api/internal/controller"
+	"himatro-api/internal/models"
+	"himatro-api/internal/repository"
+)
+
+func TestMain(m *testing.M) {
+	config.Set(config.Default())
+
+	os.Exit(m.Run())
+}
+
+// discard is the logger of the controllers under test.
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// opening is the start of the forms under test, in the default TZ of the config.
+var opening = time.Date(2024, 3, 1, 8, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+
+// newAbsentController returns a controller on a memory store holding one form,
+// open for an hour from opening, and its single participant 2115061001.
+func newAbsentController(t *testing.T, clk *clock.Fake) (*controller.AbsentController, int) {
+	t.Helper()
+
+	ctx := context.Background()
+	store := repository.NewMemoryStore(clk)
+
+	store.AddDepartemen(models.Departemen{ID: 4, Nama: "Kominfo"})
+
+	if err := store.Members().CreateAnggota(ctx, &models.AnggotaBiasa{NPM: "2115061001", Nama: "Andi"}); err != nil {
+		t.Fatalf("create anggota: %v", err)
+	}
+
+	if err := store.Members().CreatePengurus(ctx, &models.Pengurus{NPM: "2115061001", DepartemenID: 4, JabatanID: 1}); err != nil {
+		t.Fatalf("create pengurus: %v", err)
+	}
+
+	absent := controller.NewAbsentController(store, clk, discard)
+
+	formID, err := absent.RegisterNewAbsentForm(ctx, &controller.InitAbsentData{
+		Title:       "Rapat",
+		Participant: 4,
+		StartAt:     opening,
+		FinishAt:    opening.Add(time.Hour),
+	})
+
+	if err != nil {
+		t.Fatalf("register form: %v", err)
+	}
+
+	return absent, int(formID)
+}
+
+// assertCode fails unless err is an apperr.Error with the code, an empty code
+// wants no error.
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+
+	if code == "" {
+		if err != nil {
+			t.Fatalf("got error %v, want none", err)
+		}
+
+		return
+	}
+
+	appErr, ok := apperr.As(err)
+
+	if !ok || appErr.Code != code {
+		t.Fatalf("got error %v, want code %s", err, code)
+	}
+}
+
+func TestIsFormWriteable(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		code string
+	}{
+		{"before the start", opening.Add(-time.Second), apperr.CodeFormNotOpen},
+		{"at the start", opening, ""},
+		{"while open", opening.Add(30 * time.Minute), ""},
+		{"at the finish", opening.Add(time.Hour), ""},
+		{"after the finish", opening.Add(time.Hour + time.Second), apperr.CodeFormClosed},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(opening.Add(-24 * time.Hour))
+			absent, formID := newAbsentController(t, clk)
+
+			clk.Set(tt.now)
+
+			assertCode(t, absent.IsFormWriteable(context.Background(), formID), tt.code)
+		})
+	}
+}
+
+func TestExtractInitAbsentPayloadSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		startAt  string
+		finishAt string
+		code     string
+	}{
+		{"open in the future", "2024-03-01T08:00:00+07:00", "2024-03-01T09:00:00+07:00", ""},
+		{"already open", "2024-03-01T06:00:00+07:00", "2024-03-01T09:00:00+07:00", ""},
+		{"already finished", "2024-03-01T06:00:00+07:00", "2024-03-01T06:30:00+07:00", apperr.CodeInvalidSchedule},
+		{"finish before start", "2024-03-01T09:00:00+07:00", "2024-03-01T08:00:00+07:00", apperr.CodeInvalidSchedule},
+		{"start equals finish", "2024-03-01T08:00:00+07:00", "2024-03-01T08:00:00+07:00", apperr.CodeInvalidSchedule},
+		{"start equals finish in another offset", "2024-03-01T08:00:00+07:00", "2024-03-01T01:00:00Z", apperr.CodeInvalidSchedule},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(opening.Add(-time.Hour))
+			absent := controller.NewAbsentController(repository.NewMemoryStore(clk), clk, discard)
+
+			detail, err := absent.ExtractInitAbsentPayload(context.Background(), contract.CreateAbsentForm{
+				Title:       "Rapat",
+				StartAt:     tt.startAt,
+				FinishAt:    tt.finishAt,
+				Participant: "KOMINFO",
+			})
+
+			assertCode(t, err, tt.code)
+
+			if tt.code == "" && detail.TimeZone != config.Get().Server.TimeZone {
+				t.Errorf("time zone %q, want the server's %q", detail.TimeZone, config.Get().Server.TimeZone)
+			}
+		})
+	}
+}
+
+func TestUpdateAbsentListTokenExpiry(t *testing.T) {
+	expiry := time.Duration(config.Get().Auth.UpdateAbsentListTokenExpSec) * time.Second
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		code  string
+	}{
+		{"right away", 0, ""},
+		{"just before expiry", expiry - time.Second, ""},
+		{"after expiry", expiry + time.Second, apperr.CodeInvalidToken},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clk := clock.NewFake(opening.Add(time.Minute))
+			absent, formID := newAbsentController(t, clk)
+
+			token, err := absent.FillAbsentForm(ctx, formID, "2115061001", "h")
+
+			if err != nil {
+				t.Fatalf("fill: %v", err)
+			}
+
+			clk.Advance(tt.after)
+
+			cookie := &http.Cookie{Name: config.Get().Auth.UpdateAbsentListCookieName, Value: token}
+
+			assertCode(t, absent.ValidateMemberToken(ctx, cookie), tt.code)
+			assertCode(t, absent.UpdateAbsentListByAttendant(ctx, formID, "i", cookie), tt.code)
+		})
+	}
+}
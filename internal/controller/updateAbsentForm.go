@@ -1,253 +1,397 @@
 package controller
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"himatro-api/internal/db"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/contract"
+	"himatro-api/internal/logger"
 	"himatro-api/internal/models"
-	"himatro-api/internal/util"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
 )
 
-func UpdateFormTitle(absentID int, title string) (string, error) {
-	newAbsentForm := models.FormAbsensi{
-		Title: title,
+func (a *AbsentController) UpdateFormTitle(ctx context.Context, absentID int, title string, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.UpdateFormTitle")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	absentForm, err := a.getFormDetail(ctx, absentID)
+
+	if err != nil {
+		log.Info("Failed to get form detail", logger.FormIDKey, absentID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	if err := a.checkFormVersion(ctx, absentForm, version); err != nil {
+		return models.ReturnedAbsentForm{}, err
 	}
 
-	if err := updateAbsentFormDetail(newAbsentForm, absentID); err != nil {
-		util.LogErr("ERROR", "failed to update absent form title", err.Error())
-		return "", err
+	absentForm.Title = title
+
+	if err := a.saveAbsentForm(ctx, a.store, &absentForm); err != nil {
+		log.Warn("Failed to update absent form title", logger.FormIDKey, absentID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
 	}
 
-	return title, nil
+	return absentFormRepresentation(absentForm), nil
 }
 
-func UpdateParticipant(formID int, newParticipant string) error {
-	formDetail, err := getFormDetail(formID)
+// UpdateParticipant changes who is expected to fill the form. The form and its
+// regenerated absent list are written in one transaction.
+func (a *AbsentController) UpdateParticipant(ctx context.Context, formID int, newParticipant string, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.UpdateParticipant")
+	defer span.End()
 
-	if err != nil {
-		util.LogErr("ERROR", "failed to get form detail", err.Error())
-		return err
-	}
+	log := loggerFrom(ctx, a.log)
 
-	participantCode, err := validateParticipantCode(newParticipant)
+	participantCode, err := a.validateParticipantCode(ctx, newParticipant)
 
 	if err != nil {
-		util.LogErr("WARN", fmt.Sprintf("participant with code: %s is invalid", newParticipant), err.Error())
-		return fmt.Errorf("participant with code: %s is invalid", newParticipant)
+		log.Debug("Participant code is invalid", logger.FormIDKey, formID, "participant", newParticipant, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, apperr.Validation(apperr.CodeInvalidPartCode, "participant with code: %s is invalid", newParticipant)
 	}
 
-	if formDetail.Participant == participantCode {
-		return nil
-	}
+	formDetail := models.FormAbsensi{}
 
-	if err := isParticipantChangeable(formID); err != nil {
-		util.LogErr("WARN", fmt.Sprintf("participant on absentID: %d is not changeable", formID), err.Error())
-		return err
-	}
+	err = a.store.Transaction(ctx, func(tx repository.Store) error {
+		formDetail, err = a.findFormAbsent(ctx, tx, formID)
+
+		if err != nil {
+			log.Info("Failed to get form detail", logger.FormIDKey, formID, logger.ErrorKey, err)
+			return err
+		}
 
-	updateFormParticipant(formID, participantCode)
-	deleteOldAbsentList(formID)
+		if err := a.checkFormVersion(ctx, formDetail, version); err != nil {
+			return err
+		}
 
-	if err := createNewAbsentList(formID, participantCode); err != nil {
-		util.LogErr("ERROR", "Failed to generate new absent list", err.Error())
-		return err
+		if formDetail.Participant == participantCode {
+			return nil
+		}
+
+		if err := a.isParticipantChangeable(ctx, tx, formID); err != nil {
+			log.Info("Participant of absent form is not changeable", logger.FormIDKey, formID, logger.ErrorKey, err)
+			return err
+		}
+
+		formDetail.Participant = participantCode
+
+		if err := a.saveAbsentForm(ctx, tx, &formDetail); err != nil {
+			return err
+		}
+
+		if err := a.deleteOldAbsentList(ctx, tx, formID); err != nil {
+			return err
+		}
+
+		return a.createNewAbsentList(ctx, tx, formID, participantCode)
+	})
+
+	if err != nil {
+		log.Info("Failed to update participant, changes are rolled back", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
 	}
 
-	return nil
+	return absentFormRepresentation(formDetail), nil
 }
 
-func UpdateAbsentFormStartAt(formID int, startAtDate, startAtTime string) (string, error) {
-	newStartAt, err := parseDate(startAtDate, startAtTime)
+// UpdateAbsentFormStartAt reads a date and time on the wall clock of the form's time
+// zone, or a timestamp with its own offset.
+func (a *AbsentController) UpdateAbsentFormStartAt(ctx context.Context, formID int, at contract.UpdateFormTime, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.UpdateAbsentFormStartAt")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	formDetail, err := a.getFormDetail(ctx, formID)
 
 	if err != nil {
-		util.LogErr("WARN", "invalid date time string received", err.Error())
-		return "", fmt.Errorf("invalid date time string received")
+		log.Info("Failed to get form detail", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	if err := a.checkFormVersion(ctx, formDetail, version); err != nil {
+		return models.ReturnedAbsentForm{}, err
 	}
 
-	formDetail, err := getFormDetail(formID)
+	newStartAt, err := a.parseFormTime(ctx, at.At, at.Date, at.Time, formLocation(formDetail.TimeZone))
 
 	if err != nil {
-		util.LogErr("WARN", "Failed to get form detail", err.Error())
-		return "", err
+		log.Debug("Invalid date time string received", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, apperr.Validation(apperr.CodeInvalidDate, "invalid date time string received: %s", err.Error())
 	}
 
 	if newStartAt.After(formDetail.FinishAt) {
-		util.LogErr("WARN", "Failed to create new form absent can't start after it's end date", "")
-		return "", errors.New("form absent can't start after it's end date")
+		log.Info("Form absent can't start after it's end date", logger.FormIDKey, formID)
+		return models.ReturnedAbsentForm{}, apperr.Validation(apperr.CodeInvalidSchedule, "form absent can't start after it's end date")
 	}
 
-	if newStartAt == formDetail.StartAt {
-		return "", nil
+	if newStartAt.Equal(formDetail.StartAt) {
+		return absentFormRepresentation(formDetail), nil
 	}
 
-	if newStartAt.String() == formDetail.FinishAt.String() {
-		util.LogErr("WARN", "form absent cant't start and end in the same time", "")
-		return "", errors.New("form absent cant't start and end in the same time")
+	if newStartAt.Equal(formDetail.FinishAt) {
+		log.Info("Form absent can't start and end in the same time", logger.FormIDKey, formID)
+		return models.ReturnedAbsentForm{}, apperr.Validation(apperr.CodeInvalidSchedule, "form absent cant't start and end in the same time")
 	}
 
-	newAbsentForm := models.FormAbsensi{
-		StartAt: newStartAt,
-	}
+	formDetail.StartAt = newStartAt
 
-	if err := updateAbsentFormDetail(newAbsentForm, formID); err != nil {
-		util.LogErr("ERROR", "Failed to update form start at", err.Error())
-		return "", errors.New("server failure to update form details")
+	if err := a.saveAbsentForm(ctx, a.store, &formDetail); err != nil {
+		log.Error("Failed to update form time", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
 	}
 
-	return newStartAt.String(), nil
+	return absentFormRepresentation(formDetail), nil
 }
 
-func UpdateAbsentFormFinishAt(formID int, finishAtDate, finishAtTime string) (string, error) {
-	newFinishAt, err := parseDate(finishAtDate, finishAtTime)
+// UpdateAbsentFormFinishAt reads a date and time on the wall clock of the form's time
+// zone, or a timestamp with its own offset.
+func (a *AbsentController) UpdateAbsentFormFinishAt(ctx context.Context, formID int, at contract.UpdateFormTime, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.UpdateAbsentFormFinishAt")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	formDetail, err := a.getFormDetail(ctx, formID)
 
 	if err != nil {
-		util.LogErr("WARN", "invalid date time string received", err.Error())
-		return "", fmt.Errorf("invalid date time string received")
+		log.Info("Failed to get form detail", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	if err := a.checkFormVersion(ctx, formDetail, version); err != nil {
+		return models.ReturnedAbsentForm{}, err
 	}
 
-	formDetail, err := getFormDetail(formID)
+	newFinishAt, err := a.parseFormTime(ctx, at.At, at.Date, at.Time, formLocation(formDetail.TimeZone))
 
 	if err != nil {
-		util.LogErr("WARN", "Failed to get form detail", err.Error())
-		return "", err
+		log.Debug("Invalid date time string received", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, apperr.Validation(apperr.CodeInvalidDate, "invalid date time string received: %s", err.Error())
 	}
 
 	if newFinishAt.Before(formDetail.StartAt) {
-		util.LogErr("WARN", "form absent can't end before it's start date", "")
-		return "", errors.New("form absent can't end before it's start date")
+		log.Info("Form absent can't end before it's start date", logger.FormIDKey, formID)
+		return models.ReturnedAbsentForm{}, apperr.Validation(apperr.CodeInvalidSchedule, "form absent can't end before it's start date")
 	}
 
-	if newFinishAt == formDetail.FinishAt {
-		return "", nil
+	if newFinishAt.Equal(formDetail.FinishAt) {
+		return absentFormRepresentation(formDetail), nil
 	}
 
-	if newFinishAt.String() == formDetail.StartAt.String() {
-		util.LogErr("WARN", "form absent cant't start and end in the same time", "")
-		return "", errors.New("form absent cant't start and end in the same time")
+	if newFinishAt.Equal(formDetail.StartAt) {
+		log.Info("Form absent can't start and end in the same time", logger.FormIDKey, formID)
+		return models.ReturnedAbsentForm{}, apperr.Validation(apperr.CodeInvalidSchedule, "form absent cant't start and end in the same time")
 	}
 
-	newAbsentForm := models.FormAbsensi{
-		FinishAt: newFinishAt,
-	}
+	formDetail.FinishAt = newFinishAt
 
-	if err := updateAbsentFormDetail(newAbsentForm, formID); err != nil {
-		util.LogErr("ERROR", "Failed to update form start at", err.Error())
-		return "", errors.New("server failure to update form details")
+	if err := a.saveAbsentForm(ctx, a.store, &formDetail); err != nil {
+		log.Error("Failed to update form time", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
 	}
 
-	return newFinishAt.String(), nil
+	return absentFormRepresentation(formDetail), nil
 }
 
-func UpdateAbsentFormExecuseImageProof(formID int, proof bool) error {
-	absentForm := models.FormAbsensi{}
+func (a *AbsentController) UpdateAbsentFormExecuseImageProof(ctx context.Context, formID int, proof bool, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.UpdateAbsentFormExecuseImageProof")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	absentForm, err := a.getFormDetail(ctx, formID)
 
-	err := db.DB.Model(&absentForm).Where("id = ?", formID).First(&absentForm)
+	if err != nil {
+		log.Info("Failed to get form detail", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
+	}
 
-	if err.Error != nil {
-		util.LogErr("WARN", fmt.Sprintf("form with ID: %d is not exists", formID), err.Error.Error())
-		return fmt.Errorf("form with ID: %d is not exists", formID)
+	if err := a.checkFormVersion(ctx, absentForm, version); err != nil {
+		return models.ReturnedAbsentForm{}, err
 	}
 
 	absentForm.RequireExecuseImageProof = proof
 
-	db.DB.Save(&absentForm)
+	if err := a.saveAbsentForm(ctx, a.store, &absentForm); err != nil {
+		return models.ReturnedAbsentForm{}, err
+	}
 
-	return nil
+	return absentFormRepresentation(absentForm), nil
 }
 
-func UpdateAbsentFormAttendanceImageProof(formID int, proof bool) error {
-	absentForm := models.FormAbsensi{}
+func (a *AbsentController) UpdateAbsentFormAttendanceImageProof(ctx context.Context, formID int, proof bool, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.UpdateAbsentFormAttendanceImageProof")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
 
-	err := db.DB.Model(&absentForm).Where("id = ?", formID).First(&absentForm)
+	absentForm, err := a.getFormDetail(ctx, formID)
 
-	if err.Error != nil {
-		util.LogErr("WARN", fmt.Sprintf("form with ID: %d is not exists", formID), err.Error.Error())
-		return fmt.Errorf("form with ID: %d is not exists", formID)
+	if err != nil {
+		log.Info("Failed to get form detail", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	if err := a.checkFormVersion(ctx, absentForm, version); err != nil {
+		return models.ReturnedAbsentForm{}, err
 	}
 
 	absentForm.RequireAttendanceImageProof = proof
 
-	db.DB.Save(&absentForm)
+	if err := a.saveAbsentForm(ctx, a.store, &absentForm); err != nil {
+		return models.ReturnedAbsentForm{}, err
+	}
 
-	return nil
+	return absentFormRepresentation(absentForm), nil
 }
 
-func updateAbsentFormDetail(absentForm models.FormAbsensi, absentID int) error {
-	res := db.DB.Model(&models.FormAbsensi{}).Where("id = ?", absentID).Updates(&absentForm)
+func (a *AbsentController) UpdateAbsentFormResultVisibility(ctx context.Context, formID int, visibility string, version uint) (models.ReturnedAbsentForm, error) {
+	ctx, span := tracing.Start(ctx, "controller.UpdateAbsentFormResultVisibility")
+	defer span.End()
+
+	log := loggerFrom(ctx, a.log)
+
+	absentForm, err := a.getFormDetail(ctx, formID)
 
-	if res.RowsAffected == 0 {
-		util.LogErr("WARN", fmt.Sprintf("absent form for ID: %d is not found", absentID), res.Error.Error())
-		return fmt.Errorf("absent form for ID: %d is not found", absentID)
+	if err != nil {
+		log.Info("Failed to get form detail", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return models.ReturnedAbsentForm{}, err
 	}
 
-	return nil
+	if err := a.checkFormVersion(ctx, absentForm, version); err != nil {
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	absentForm.ResultVisibility = visibility
+
+	if err := a.saveAbsentForm(ctx, a.store, &absentForm); err != nil {
+		return models.ReturnedAbsentForm{}, err
+	}
+
+	return absentFormRepresentation(absentForm), nil
 }
 
-func deleteOldAbsentList(formID int) {
-	absentList := &models.AbsentList{
-		FormAbsensiID: uint(formID),
+// checkFormVersion rejects a write based on a stale version of the form.
+// Version 0 means the client sent no If-Match, so any version is accepted.
+func (a *AbsentController) checkFormVersion(ctx context.Context, absentForm models.FormAbsensi, version uint) error {
+	log := loggerFrom(ctx, a.log)
+
+	if version == 0 || version == absentForm.Version {
+		return nil
 	}
 
-	db.DB.Model(&models.AbsentList{}).
-		Where(&models.AbsentList{FormAbsensiID: uint(formID)}).
-		Delete(absentList)
+	log.Info("Absent form was modified since the client read it", logger.FormIDKey, absentForm.ID, "version", version, "currentVersion", absentForm.Version)
+
+	return apperr.PreconditionFailed(apperr.CodeVersionMismatch, absentFormRepresentation(absentForm), "absent form with ID: %d was modified by someone else", absentForm.ID)
 }
 
-func createNewAbsentList(formID int, participantCode int) error {
-	NPMs, err := getAllNPMFromDepartemenID(participantCode)
+// saveAbsentForm writes every field of the form and increments its version, but
+// only if nobody else saved the form since it was read.
+func (a *AbsentController) saveAbsentForm(ctx context.Context, tx repository.Store, absentForm *models.FormAbsensi) error {
+	log := loggerFrom(ctx, a.log)
+
+	readVersion := absentForm.Version
+	absentForm.Version = readVersion + 1
+
+	err := tx.Forms().UpdateVersioned(ctx, absentForm, readVersion)
+
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		absentForm.Version = readVersion
+		log.Error("Failed to update absent form", logger.FormIDKey, absentForm.ID, logger.ErrorKey, err)
+		return apperr.Internal(err, "server failure to update form details")
+	}
+
+	absentForm.Version = readVersion
+
+	current, err := a.findFormAbsent(ctx, tx, int(absentForm.ID))
 
 	if err != nil {
-		util.LogErr("ERROR", "absent list creation failed", err.Error())
-		return errors.New("absent list creation failed")
+		return err
 	}
 
-	absentList := generateAbsentList(NPMs, uint(formID))
+	log.Info("Absent form was modified while updating it", logger.FormIDKey, absentForm.ID, "version", readVersion, "currentVersion", current.Version)
+
+	return apperr.PreconditionFailed(apperr.CodeVersionMismatch, absentFormRepresentation(current), "absent form with ID: %d was modified by someone else", absentForm.ID)
+}
+
+func (a *AbsentController) deleteOldAbsentList(ctx context.Context, tx repository.Store, formID int) error {
+	log := loggerFrom(ctx, a.log)
 
-	db.DB.Create(&absentList)
+	if err := tx.AbsentLists().DeleteByForm(ctx, uint(formID)); err != nil {
+		log.Error("Failed to delete old absent list", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to delete old absent list")
+	}
 
 	return nil
 }
 
-func getFormDetail(formID int) (models.FormAbsensi, error) {
-	formAbsent := models.FormAbsensi{}
+// absentListBatchSize is how many absent list rows go in one INSERT.
+const absentListBatchSize = 200
+
+func (a *AbsentController) createNewAbsentList(ctx context.Context, tx repository.Store, formID int, participantCode int) error {
+	log := loggerFrom(ctx, a.log)
 
-	res := db.DB.Model(formAbsent).Where("id = ?", formID).Find(&formAbsent)
+	NPMs, err := a.getAllNPMFromDepartemenID(ctx, tx, participantCode)
 
-	if res.RowsAffected == 0 {
-		util.LogErr("WARN", fmt.Sprintf("absent form for ID: %d is not found", formID), res.Error.Error())
-		return formAbsent, fmt.Errorf("form with ID: %d is not found", formID)
+	if err != nil {
+		log.Error("Absent list creation failed", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return apperr.Internal(err, "absent list creation failed")
 	}
 
-	return formAbsent, nil
+	absentList := generateAbsentList(NPMs, uint(formID))
+
+	if err := tx.AbsentLists().CreateInBatches(ctx, absentList, absentListBatchSize); err != nil {
+		log.Error("Absent list creation failed", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return apperr.Internal(err, "absent list creation failed")
+	}
+
+	return nil
 }
 
-func updateFormParticipant(formID int, newParticipant int) {
-	absentForm := models.FormAbsensi{}
+func (a *AbsentController) getFormDetail(ctx context.Context, formID int) (models.FormAbsensi, error) {
+	return a.findFormAbsent(ctx, a.store, formID)
+}
+
+func (a *AbsentController) findFormAbsent(ctx context.Context, tx repository.Store, formID int) (models.FormAbsensi, error) {
+	log := loggerFrom(ctx, a.log)
 
-	db.DB.Model(&absentForm).Where("id = ?", formID).First(&absentForm)
+	formAbsent, err := tx.Forms().FindByID(ctx, uint(formID))
 
-	absentForm.Participant = newParticipant
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Info("Absent form not found", logger.FormIDKey, formID)
+		return formAbsent, apperr.NotFound(apperr.CodeFormNotFound, "form with ID: %d is not found", formID)
+	}
 
-	db.DB.Save(&absentForm)
+	if err != nil {
+		log.Error("Failed to lookup the absent form", logger.FormIDKey, formID, logger.ErrorKey, err)
+		return formAbsent, apperr.Internal(err, "system failure to lookup absent form")
+	}
+
+	return formAbsent, nil
 }
 
-func isParticipantChangeable(absentID int) error {
-	absentLists := []models.AbsentList{}
+func (a *AbsentController) isParticipantChangeable(ctx context.Context, tx repository.Store, absentID int) error {
+	log := loggerFrom(ctx, a.log)
 
-	res := db.DB.Model(&models.AbsentList{}).
-		Where(&models.AbsentList{
-			FormAbsensiID: uint(absentID),
-		}).Find(&absentLists)
+	absentLists, err := tx.AbsentLists().ListByForm(ctx, uint(absentID))
 
-	if res.Error != nil {
-		util.LogErr("ERROR", "failed to change participant of an absent form", res.Error.Error())
-		return errors.New("failed to change participant of an absent form")
+	if err != nil {
+		log.Error("Failed to change participant of an absent form", logger.FormIDKey, absentID, logger.ErrorKey, err)
+		return apperr.Internal(err, "failed to change participant of an absent form")
 	}
 
 	for _, absentList := range absentLists {
 		if absentList.Keterangan != "?" {
-			util.LogErr("ERROR", fmt.Sprintf("participant of absent form with ID: %d can't be changed because some participants are already fill it", absentID), "")
-			return fmt.Errorf("participant of absent form with ID: %d can't be changed because some participants are already fill it", absentID)
+			log.Info("Participant of absent form can't be changed because some participants are already fill it", logger.FormIDKey, absentID)
+			return apperr.Conflict(apperr.CodeParticipantLocked, "participant of absent form with ID: %d can't be changed because some participants are already fill it", absentID)
 		}
 	}
 
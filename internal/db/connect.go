@@ -2,26 +2,112 @@ package db
 
 import (
 	"himatro-api/internal/config"
-	"himatro-api/internal/util"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/metrics"
+	"himatro-api/internal/tracing"
 	"log"
+	"time"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 var err error
 
+// Connect opens the database in DB_DRIVER. The first connection is retried with
+// a doubling backoff, so the API can start before the Postgres container is ready.
 func Connect() {
-	dsn := config.DBConnString()
+	cfg := config.Get().Database
+	driver := cfg.Driver
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = openWithRetry(cfg)
 
 	if err != nil {
-		util.LogErr("ERROR", "Failed to connect to Postgres Server", err.Error())
-		log.Fatal("Failed to connect to Postgres Server")
-		panic("Connection to Postgres Server failed")
+		logger.Default().Error("Failed to connect to the database", "driver", driver, logger.ErrorKey, err)
+		log.Fatal("Failed to connect to the database")
+		panic("Connection to the database failed")
 	}
 
-	log.Print("Successfully connected to Postgres Server")
+	sqlDB, err := DB.DB()
+
+	if err != nil {
+		logger.Default().Error("Failed to get the database connection pool", logger.ErrorKey, err)
+		log.Fatal("Failed to get the database connection pool")
+	}
+
+	if driver == config.DriverSQLite {
+		// SQLite allows one writer at a time, and every connection to :memory:
+		// would open its own empty database.
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+
+	if err := DB.Use(metrics.GormPlugin{}); err != nil {
+		logger.Default().Error("Failed to register GORM metrics plugin", logger.ErrorKey, err)
+	}
+
+	if err := DB.Use(tracing.GormPlugin{}); err != nil {
+		logger.Default().Error("Failed to register GORM tracing plugin", logger.ErrorKey, err)
+	}
+
+	logger.Default().Info("Successfully connected to the database", "driver", driver)
+}
+
+func openWithRetry(cfg config.Database) (*gorm.DB, error) {
+	backoff := cfg.ConnectBackoff
+
+	for attempt := 0; ; attempt++ {
+		conn, err := gorm.Open(dialector(cfg), &gorm.Config{})
+
+		if err == nil {
+			return conn, nil
+		}
+
+		// A failed Open may still have opened a connection pool, which would
+		// leak once per attempt.
+		closePool(conn)
+
+		if attempt >= cfg.ConnectRetries {
+			return nil, err
+		}
+
+		logger.Default().Warn("Failed to connect to the database, retrying",
+			"driver", cfg.Driver,
+			"attempt", attempt+1,
+			"retries", cfg.ConnectRetries,
+			"wait", backoff,
+			logger.ErrorKey, err,
+		)
+
+		time.Sleep(backoff)
+
+		backoff *= 2
+
+		if backoff > cfg.ConnectMaxBackoff {
+			backoff = cfg.ConnectMaxBackoff
+		}
+	}
+}
+
+func closePool(conn *gorm.DB) {
+	if conn == nil {
+		return
+	}
+
+	if sqlDB, err := conn.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+func dialector(cfg config.Database) gorm.Dialector {
+	if cfg.Driver == config.DriverSQLite {
+		return sqlite.Open(cfg.SQLiteDSN())
+	}
+
+	return postgres.Open(cfg.ConnString())
 }
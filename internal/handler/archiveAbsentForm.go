@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ArchiveAbsentForm soft deletes an absent form. It honors If-Match like the
+// update routes.
+func (h *Handler) ArchiveAbsentForm(c echo.Context) error {
+	absentID, err := strconv.Atoi(c.Param("absentID"))
+
+	if err != nil {
+		return errInvalidParam("absentID")
+	}
+
+	version, err := ifMatchVersion(c)
+
+	if err != nil {
+		return err
+	}
+
+	absentForm, err := h.absent.ArchiveAbsentForm(c.Request().Context(), absentID, version)
+
+	if err != nil {
+		return err
+	}
+
+	setETag(c, absentForm)
+
+	return c.JSON(http.StatusOK, SuccessAbsentForm{
+		OK:   true,
+		Form: absentForm,
+	})
+}
+
+// RestoreAbsentForm brings back an archived absent form with its absent list.
+func (h *Handler) RestoreAbsentForm(c echo.Context) error {
+	absentID, err := strconv.Atoi(c.Param("absentID"))
+
+	if err != nil {
+		return errInvalidParam("absentID")
+	}
+
+	absentForm, err := h.absent.RestoreAbsentForm(c.Request().Context(), absentID)
+
+	if err != nil {
+		return err
+	}
+
+	setETag(c, absentForm)
+
+	return c.JSON(http.StatusOK, SuccessAbsentForm{
+		OK:   true,
+		Form: absentForm,
+	})
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"fmt"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/util"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorHandler is the echo.HTTPErrorHandler of the API. Handlers just return the
+// error they got, and this writes it as an ErrorMessage with the matching status code.
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, body := errorResponse(c, err)
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, body)
+	}
+
+	if err != nil {
+		requestLogger(c).Error("Failed to write error response", logger.ErrorKey, err)
+	}
+}
+
+func errorResponse(c echo.Context, err error) (int, ErrorMessage) {
+	body := ErrorMessage{
+		OK:        false,
+		RequestID: requestID(c),
+	}
+
+	if appErr, ok := apperr.As(err); ok {
+		if appErr.Kind == apperr.KindInternal {
+			requestLogger(c).Error(appErr.Message, logger.ErrorKey, appErr.Err)
+		}
+
+		body.Code = appErr.Code
+		body.Message = appErr.Message
+		body.Details = appErr.Details
+		body.Current = appErr.Current
+
+		if resource, ok := appErr.Current.(eTagger); ok {
+			setETag(c, resource)
+		}
+
+		return appErr.Status(), body
+	}
+
+	if httpErr, ok := err.(*echo.HTTPError); ok {
+		body.Code = httpErrorCode(httpErr.Code)
+		body.Message = fmt.Sprintf("%v", httpErr.Message)
+
+		return httpErr.Code, body
+	}
+
+	requestLogger(c).Error("Unhandled error", logger.ErrorKey, err)
+
+	body.Code = apperr.CodeInternal
+	body.Message = http.StatusText(http.StatusInternalServerError)
+
+	return http.StatusInternalServerError, body
+}
+
+func httpErrorCode(status int) string {
+	switch status {
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return apperr.CodeRouteMissing
+	case http.StatusUnauthorized:
+		return apperr.CodeUnauthorized
+	case http.StatusBadRequest:
+		return apperr.CodeInvalidPayload
+	case http.StatusInternalServerError:
+		return apperr.CodeInternal
+	default:
+		return apperr.CodeHTTPError
+	}
+}
+
+func errInvalidParam(name string) error {
+	return apperr.BadRequest(apperr.CodeInvalidParam, "%s must be a valid numeric string", name)
+}
+
+func errInvalidPayload() error {
+	return apperr.BadRequest(apperr.CodeInvalidPayload, "Invalid type of JSON Payload received")
+}
+
+func errValidation(err error) error {
+	return apperr.Validation(apperr.CodeValidationFailed, "JSON payload validation error").
+		WithDetails(util.ExtractValidationErrorMsg(err))
+}
+
+func requestLogger(c echo.Context) *slog.Logger {
+	if l, ok := logger.FromContext(c.Request().Context()); ok {
+		return l
+	}
+
+	return logger.Default()
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"himatro-api/internal/apperr"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+type eTagger interface {
+	ETag() string
+}
+
+// ifMatchVersion reads the form version from the If-Match header.
+// 0 means the request has no precondition.
+func ifMatchVersion(c echo.Context) (uint, error) {
+	raw := strings.TrimSpace(c.Request().Header.Get(HeaderIfMatch))
+
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(raw, `"`), 10, 64)
+
+	if err != nil || version == 0 || !strings.HasPrefix(raw, `"`) || !strings.HasSuffix(raw, `"`) {
+		return 0, apperr.BadRequest(apperr.CodeInvalidParam, "If-Match must be a single ETag returned by this API")
+	}
+
+	return uint(version), nil
+}
+
+func setETag(c echo.Context, resource eTagger) {
+	c.Response().Header().Set(HeaderETag, resource.ETag())
+}
@@ -1,10 +1,10 @@
 package handler
 
 import (
-	"fmt"
+	"himatro-api/internal/apperr"
 	"himatro-api/internal/config"
 	"himatro-api/internal/contract"
-	"himatro-api/internal/controller"
+	"himatro-api/internal/metrics"
 	"himatro-api/internal/util"
 	"net/http"
 	"strconv"
@@ -13,122 +13,90 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func CheckAbsentForm(c echo.Context) error {
+func (h *Handler) CheckAbsentForm(c echo.Context) error {
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Absent ID must be a valid numeric string.",
-		})
+		return errInvalidParam("absentID")
 	}
 
-	if err := controller.IsFormWriteable(absentID); err != nil {
-		return c.JSON(http.StatusForbidden, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Request forbidden because: %s", err.Error()),
-		})
+	if err := h.absent.IsFormWriteable(c.Request().Context(), absentID); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusOK)
 }
 
-func FillAbsentForm(c echo.Context) error {
+func (h *Handler) FillAbsentForm(c echo.Context) error {
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Absent ID must be a valid numeric string.",
-		})
+		return errInvalidParam("absentID")
 	}
 
-	if err := controller.IsFormWriteable(absentID); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Failed to fill absent form because: %s", err.Error()),
-		})
+	if err := h.absent.IsFormWriteable(c.Request().Context(), absentID); err != nil {
+		metrics.ObserveAttendanceSubmission(absentID, metrics.SubmissionRejected)
+
+		return err
 	}
 
 	payload := contract.FillAbsentList{}
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON Payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
-	updateToken, err := controller.FillAbsentForm(absentID, payload.NPM, payload.Keterangan)
+	updateToken, err := h.absent.FillAbsentForm(c.Request().Context(), absentID, payload.NPM, payload.Keterangan)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Failed to fill absent form because: %s", err.Error()),
-		})
+		metrics.ObserveAttendanceSubmission(absentID, metrics.SubmissionRejected)
+
+		return err
 	}
 
-	updateTokenExpiresSec := config.UpdateAbsentListTokenExpSec()
+	metrics.ObserveAttendanceSubmission(absentID, payload.Keterangan)
+
+	updateTokenExpiresSec := config.Get().Auth.UpdateAbsentListTokenExpSec
 
 	cookie := new(http.Cookie)
-	cookie.Name = config.UpdateAbsentListCookieName()
+	cookie.Name = config.Get().Auth.UpdateAbsentListCookieName
 	cookie.Value = updateToken
-	cookie.Expires = time.Now().Add(time.Second * time.Duration(updateTokenExpiresSec))
+	cookie.Expires = h.clock.Now().Add(time.Second * time.Duration(updateTokenExpiresSec))
 
 	c.SetCookie(cookie)
 
 	return nil
 }
 
-func UpdateAbsentListByAttendant(c echo.Context) error {
-	cookie, err := c.Cookie(config.UpdateAbsentListCookieName())
+func (h *Handler) UpdateAbsentListByAttendant(c echo.Context) error {
+	cookie, err := c.Cookie(config.Get().Auth.UpdateAbsentListCookieName)
 
 	if err != nil {
-		return c.JSON(http.StatusForbidden, ErrorMessage{
-			OK:      false,
-			Message: "Please provide update absent token.",
-		})
+		return apperr.Unauthorized(apperr.CodeInvalidToken, "Please provide update absent token.")
 	}
 
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "absentID must be a valid numeric string",
-		})
+		return errInvalidParam("absentID")
 	}
 
 	payload := contract.UpdateKeteranganAbsent{}
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON Payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
-	if err := controller.UpdateAbsentListByAttendant(absentID, payload.Keterangan, cookie); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Failed to update absent list because: %s", err.Error()),
-		})
+	if err := h.absent.UpdateAbsentListByAttendant(c.Request().Context(), absentID, payload.Keterangan, cookie); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusAccepted)
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"himatro-api/internal/clock"
+	"himatro-api/internal/controller"
+)
+
+// Handler serves the API routes with the controllers it was built with.
+type Handler struct {
+	clock  clock.Clock
+	absent *controller.AbsentController
+	auth   *controller.AuthController
+	member *controller.MemberController
+	health *controller.HealthController
+}
+
+func New(clk clock.Clock, absent *controller.AbsentController, auth *controller.AuthController, member *controller.MemberController, health *controller.HealthController) *Handler {
+	return &Handler{
+		clock:  clk,
+		absent: absent,
+		auth:   auth,
+		member: member,
+		health: health,
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"himatro-api/internal/controller"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func Liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, HealthStatus{
+		Status: controller.HealthStatusOK,
+		Components: map[string]controller.ComponentHealth{
+			"process": {Status: controller.HealthStatusOK},
+		},
+	})
+}
+
+func (h *Handler) Readiness(c echo.Context) error {
+	components, ready := h.health.CheckReadiness(c.Request().Context())
+
+	if !ready {
+		return c.JSON(http.StatusServiceUnavailable, HealthStatus{
+			Status:     controller.HealthStatusFail,
+			Components: components,
+		})
+	}
+
+	return c.JSON(http.StatusOK, HealthStatus{
+		Status:     controller.HealthStatusOK,
+		Components: components,
+	})
+}
@@ -2,56 +2,33 @@ package handler
 
 import (
 	"himatro-api/internal/contract"
-	"himatro-api/internal/controller"
 	"himatro-api/internal/util"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 )
 
-func InitAbsent(c echo.Context) error {
+func (h *Handler) InitAbsent(c echo.Context) error {
 	createAbsentPayload := contract.CreateAbsentForm{}
 
 	if err := c.Bind(&createAbsentPayload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&createAbsentPayload); err != nil {
-		return c.JSON(http.StatusOK, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
-	initAbsentPayload, err := controller.ExtractInitAbsentPayload(createAbsentPayload)
+	initAbsentPayload, err := h.absent.ExtractInitAbsentPayload(c.Request().Context(), createAbsentPayload)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: err.Error(),
-		})
+		return err
 	}
 
-	absentID, err := controller.RegisterNewAbsentForm(&initAbsentPayload)
+	absentID, err := h.absent.RegisterNewAbsentForm(c.Request().Context(), &initAbsentPayload)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorMessage{
-			OK:      false,
-			Message: err.Error(),
-		})
-	}
-
-	err = controller.InitAbsentList(&initAbsentPayload, absentID)
-
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorMessage{
-			OK:      false,
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, SuccessCreateAbsent{
@@ -63,5 +40,7 @@ func InitAbsent(c echo.Context) error {
 		FinishAt:                    initAbsentPayload.FinishAt,
 		RequireAttendanceImageProof: initAbsentPayload.RequireAttendanceImageProof,
 		RequireExecuseImageProof:    initAbsentPayload.RequireExecuseImageProof,
+		ResultVisibility:            initAbsentPayload.ResultVisibility,
+		TimeZone:                    initAbsentPayload.TimeZone,
 	})
 }
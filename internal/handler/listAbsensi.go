@@ -1,31 +1,57 @@
 package handler
 
 import (
-	"fmt"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/auth"
+	"himatro-api/internal/config"
 	"himatro-api/internal/controller"
+	"himatro-api/internal/models"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
-func GetAbsentResult(c echo.Context) error {
+func (h *Handler) GetAbsentResult(c echo.Context) error {
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Param: absentID must be a valid numeric string.",
-		})
+		return errInvalidParam("absentID")
 	}
 
-	absentList, err := controller.GetAbsentListResult(absentID)
+	visibility, err := h.absent.GetFormResultVisibility(c.Request().Context(), absentID)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Failed to get requested absent list because: %s", err.Error()),
-		})
+		return err
+	}
+
+	isAdmin := h.isAdminRequest(c)
+
+	if !isAdmin && visibility == models.ResultVisibilityAdmins {
+		return apperr.Forbidden(apperr.CodeResultForbidden, "This absent result is only visible to admins.")
+	}
+
+	if !isAdmin && visibility == models.ResultVisibilityMembers {
+		cookie, err := c.Cookie(config.Get().Auth.UpdateAbsentListCookieName)
+
+		if err != nil {
+			return apperr.Forbidden(apperr.CodeResultForbidden, "This absent result is only visible to members. Please provide your absent token.")
+		}
+
+		if err := h.absent.ValidateMemberToken(c.Request().Context(), cookie); err != nil {
+			return err
+		}
+	}
+
+	absentList, err := h.absent.GetAbsentListResult(c.Request().Context(), absentID)
+
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin && visibility == models.ResultVisibilityMasked {
+		absentList = controller.MaskAbsentListResult(absentList)
 	}
 
 	return c.JSON(http.StatusOK, SuccessListAbsent{
@@ -36,20 +62,19 @@ func GetAbsentResult(c echo.Context) error {
 	})
 }
 
-func GetAbsentFormsDetails(c echo.Context) error {
+func (h *Handler) GetAbsentFormsDetails(c echo.Context) error {
 	limit, err := strconv.Atoi(c.QueryParam("limit"))
 
 	if err != nil {
 		limit = 0
 	}
 
-	absentForms, err := controller.GetAbsentFormsDetails(limit)
+	includeArchived, _ := strconv.ParseBool(c.QueryParam("archived"))
+
+	absentForms, err := h.absent.GetAbsentFormsDetails(c.Request().Context(), limit, includeArchived)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Server failure to fulfill the request due to: %s", err.Error()),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, SuccessFormAbsentDetails{
@@ -59,3 +84,15 @@ func GetAbsentFormsDetails(c echo.Context) error {
 		List:    absentForms,
 	})
 }
+
+// isAdminRequest reports whether the request carries a valid admin login token.
+// Public routes use it to decide whether to show the unrestricted data.
+func (h *Handler) isAdminRequest(c echo.Context) bool {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+
+	return auth.ValidateLoginToken(h.clock, strings.TrimPrefix(header, "Bearer ")) == nil
+}
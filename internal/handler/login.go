@@ -1,51 +1,58 @@
 package handler
 
 import (
-	"himatro-api/internal/controller"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/metrics"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 )
 
-func Login(c echo.Context) error {
-	NPM, plainPassword, err := controller.ExtractLoginPayload(c)
+func (h *Handler) Login(c echo.Context) error {
+	NPM, plainPassword, err := h.auth.ExtractLoginPayload(c)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: err.Error(),
-		})
+		observeLoginFailure(err)
+
+		return err
 	}
 
-	encryptedPassword, err := controller.GetUserPassword(NPM)
+	encryptedPassword, err := h.auth.GetUserPassword(c.Request().Context(), NPM)
 
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, ErrorMessage{
-			OK:      false,
-			Message: err.Error(),
-		})
+		observeLoginFailure(err)
+
+		return err
 	}
 
-	err = controller.ValidatePassword(plainPassword, encryptedPassword)
+	err = h.auth.ValidatePassword(c.Request().Context(), plainPassword, encryptedPassword)
 
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, ErrorMessage{
-			OK:      false,
-			Message: err.Error(),
-		})
+		observeLoginFailure(err)
+
+		return err
 	}
 
-	loginToken, err := controller.CreateLoginToken(c, NPM)
+	loginToken, err := h.auth.CreateLoginToken(c, NPM)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorMessage{
-			OK:      false,
-			Message: "Server error when creating login token.",
-		})
+		return err
 	}
 
+	metrics.ObserveLogin(metrics.LoginSuccess)
+
 	return c.JSON(http.StatusOK, LoginTokenResp{
 		OK:    true,
 		Token: loginToken,
 	})
 }
+
+// observeLoginFailure counts rejected logins. A server side failure says nothing
+// about the credentials, so it is left to the error metrics.
+func observeLoginFailure(err error) {
+	if apperr.IsKind(err, apperr.KindInternal) {
+		return
+	}
+
+	metrics.ObserveLogin(metrics.LoginFailure)
+}
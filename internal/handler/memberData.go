@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) ExportMemberData(c echo.Context) error {
+	NPM := c.Param("NPM")
+
+	memberData, err := h.member.ExportMemberData(c.Request().Context(), NPM)
+
+	if err != nil {
+		return err
+	}
+
+	if c.QueryParam("format") != "zip" {
+		return c.JSON(http.StatusOK, memberData)
+	}
+
+	archive, err := h.member.ZipMemberData(c.Request().Context(), memberData)
+
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=member_%s.zip", NPM))
+
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
+
+func (h *Handler) EraseMemberData(c echo.Context) error {
+	NPM := c.Param("NPM")
+
+	if err := h.member.EraseMemberData(c.Request().Context(), NPM); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, SuccessEraseMember{
+		OK:      true,
+		Message: "Member data erased",
+	})
+}
@@ -0,0 +1,10 @@
+package handler
+
+import (
+	"himatro-api/internal/metrics"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var Metrics = echo.WrapHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"himatro-api/internal/apperr"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+
+// PatchAbsentForm updates any fields of an absent form at once with a JSON merge patch (RFC 7396).
+func (h *Handler) PatchAbsentForm(c echo.Context) error {
+	absentID, err := strconv.Atoi(c.Param("absentID"))
+
+	if err != nil {
+		return errInvalidParam("absentID")
+	}
+
+	version, err := ifMatchVersion(c)
+
+	if err != nil {
+		return err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+
+	if err != nil || (mediaType != MIMEApplicationMergePatchJSON && mediaType != echo.MIMEApplicationJSON) {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be "+MIMEApplicationMergePatchJSON)
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+
+	if err != nil {
+		return apperr.BadRequest(apperr.CodeInvalidPayload, "failed to read request body")
+	}
+
+	absentForm, err := h.absent.PatchAbsentForm(c.Request().Context(), absentID, patch, version)
+
+	if err != nil {
+		return err
+	}
+
+	setETag(c, absentForm)
+
+	return c.JSON(http.StatusOK, SuccessAbsentForm{
+		OK:   true,
+		Form: absentForm,
+	})
+}
+
+// GetAbsentForm returns the form with its ETag, to be sent back in If-Match when updating it.
+func (h *Handler) GetAbsentForm(c echo.Context) error {
+	absentID, err := strconv.Atoi(c.Param("absentID"))
+
+	if err != nil {
+		return errInvalidParam("absentID")
+	}
+
+	absentForm, err := h.absent.GetAbsentForm(c.Request().Context(), absentID)
+
+	if err != nil {
+		return err
+	}
+
+	setETag(c, absentForm)
+
+	if c.Request().Header.Get(HeaderIfNoneMatch) == absentForm.ETag() {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSON(http.StatusOK, SuccessAbsentForm{
+		OK:   true,
+		Form: absentForm,
+	})
+}
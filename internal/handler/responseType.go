@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"himatro-api/internal/controller"
 	"himatro-api/internal/models"
 	"time"
+
+	"github.com/labstack/echo/v4"
 )
 
 type AbsentListSuccessMessage struct {
@@ -11,15 +14,21 @@ type AbsentListSuccessMessage struct {
 	Result []models.AnggotaBiasa `json:"result"`
 }
 
+// ErrorMessage is the body of every error response. Code is one of the stable
+// codes in package apperr, Details lists the failed fields of a JSON payload and
+// Current is the current state of a resource that failed a precondition.
 type ErrorMessage struct {
-	OK      bool   `json:"ok"`
-	Message string `json:"message"`
+	OK        bool        `json:"ok"`
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   []string    `json:"details,omitempty"`
+	Current   interface{} `json:"current,omitempty"`
+	RequestID string      `json:"requestID,omitempty"`
 }
 
-type JSONPayloadValidationError struct {
-	OK      bool     `json:"ok"`
-	Message string   `json:"message"`
-	Details []string `json:"details"`
+type HealthStatus struct {
+	Status     string                                `json:"status"`
+	Components map[string]controller.ComponentHealth `json:"components"`
 }
 
 type LoginTokenResp struct {
@@ -36,6 +45,8 @@ type SuccessCreateAbsent struct {
 	FinishAt                    time.Time `json:"finishAt"`
 	RequireAttendanceImageProof bool      `json:"requireAttendanceImageProof"`
 	RequireExecuseImageProof    bool      `json:"requireExecuseImageProof"`
+	ResultVisibility            string    `json:"resultVisibility"`
+	TimeZone                    string    `json:"timeZone"`
 }
 
 type SuccessListAbsent struct {
@@ -52,9 +63,24 @@ type SuccessUpdateForm struct {
 	Value     string `json:"value"`
 }
 
+type SuccessAbsentForm struct {
+	OK   bool                      `json:"ok"`
+	Form models.ReturnedAbsentForm `json:"form"`
+}
+
 type SuccessFormAbsentDetails struct {
 	OK      bool                               `json:"ok"`
 	Message string                             `json:"message"`
 	Total   int                                `json:"total"`
 	List    []models.ReturnedFormAbsentDetails `json:"list"`
 }
+
+type SuccessEraseMember struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// requestID returns the ID set by middleware.RequestID, so it can be sent back in error payloads.
+func requestID(c echo.Context) string {
+	return c.Response().Header().Get(echo.HeaderXRequestID)
+}
@@ -3,93 +3,85 @@ package handler
 import (
 	"fmt"
 	"himatro-api/internal/contract"
-	"himatro-api/internal/controller"
 	"himatro-api/internal/util"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
-func UpdateFormTitle(c echo.Context) error {
+func (h *Handler) UpdateFormTitle(c echo.Context) error {
 	payload := contract.UpdateFormTitle{}
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON Payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("AbsentID with ID %s is invalid.", c.Param("absentID")),
-		})
+		return errInvalidParam("absentID")
 	}
 
-	newValue, err := controller.UpdateFormTitle(absentID, payload.Title)
+	version, err := ifMatchVersion(c)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Form with ID: %d is not updated because: %s", absentID, err.Error()),
-		})
+		return err
 	}
 
+	absentForm, err := h.absent.UpdateFormTitle(c.Request().Context(), absentID, payload.Title, version)
+
+	if err != nil {
+		return err
+	}
+
+	setETag(c, absentForm)
+
 	return c.JSON(http.StatusOK, SuccessUpdateForm{
 		OK:        true,
 		Message:   "Update success",
 		FieldName: "title",
-		Value:     newValue,
+		Value:     absentForm.Title,
 	})
 }
 
-func UpdateFormParticipant(c echo.Context) error {
+func (h *Handler) UpdateFormParticipant(c echo.Context) error {
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Update participant in absent form is failed because absentID is not a number.",
-		})
+		return errInvalidParam("absentID")
+	}
+
+	version, err := ifMatchVersion(c)
+
+	if err != nil {
+		return err
 	}
 
 	payload := contract.UpdateFormParticipant{}
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON Payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
-	if err = controller.UpdateParticipant(absentID, payload.Participant); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Update participant in absent form is failed because: %s", err.Error()),
-		})
+	absentForm, err := h.absent.UpdateParticipant(c.Request().Context(), absentID, payload.Participant, version)
+
+	if err != nil {
+		return err
 	}
 
+	setETag(c, absentForm)
+
 	return c.JSON(http.StatusOK, SuccessUpdateForm{
 		OK:        true,
 		Message:   "Update success",
@@ -98,128 +90,115 @@ func UpdateFormParticipant(c echo.Context) error {
 	})
 }
 
-func UpdateFormStartAt(c echo.Context) error {
+func (h *Handler) UpdateFormStartAt(c echo.Context) error {
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "AbsentID must be a valid number.",
-		})
+		return errInvalidParam("absentID")
+	}
+
+	version, err := ifMatchVersion(c)
+
+	if err != nil {
+		return err
 	}
 
 	payload := contract.UpdateFormTime{}
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON Payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
-	startTime, err := controller.UpdateAbsentFormStartAt(absentID, payload.Date, payload.Time)
+	absentForm, err := h.absent.UpdateAbsentFormStartAt(c.Request().Context(), absentID, payload, version)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Update form startAt failed because: %s", err.Error()),
-		})
+		return err
 	}
 
+	setETag(c, absentForm)
+
 	return c.JSON(http.StatusOK, SuccessUpdateForm{
 		OK:        true,
 		Message:   "Update Success",
 		FieldName: "startAt",
-		Value:     startTime,
+		Value:     absentForm.StartAt.Format(time.RFC3339),
 	})
 }
 
-func UpdateAbsentFormFinishAt(c echo.Context) error {
+func (h *Handler) UpdateAbsentFormFinishAt(c echo.Context) error {
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "AbsentID must be a valid number.",
-		})
+		return errInvalidParam("absentID")
+	}
+
+	version, err := ifMatchVersion(c)
+
+	if err != nil {
+		return err
 	}
 
 	payload := contract.UpdateFormTime{}
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON Payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
-	finishTime, err := controller.UpdateAbsentFormFinishAt(absentID, payload.Date, payload.Time)
+	absentForm, err := h.absent.UpdateAbsentFormFinishAt(c.Request().Context(), absentID, payload, version)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Update form startAt failed because: %s", err.Error()),
-		})
+		return err
 	}
 
+	setETag(c, absentForm)
+
 	return c.JSON(http.StatusOK, SuccessUpdateForm{
 		OK:        true,
 		Message:   "Update Success",
 		FieldName: "finishAt",
-		Value:     finishTime,
+		Value:     absentForm.FinishAt.Format(time.RFC3339),
 	})
 }
 
-func UpdateAbsentFormExecuseImageProof(c echo.Context) error {
+func (h *Handler) UpdateAbsentFormExecuseImageProof(c echo.Context) error {
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "AbsentID must be a valid number.",
-		})
+		return errInvalidParam("absentID")
+	}
+
+	version, err := ifMatchVersion(c)
+
+	if err != nil {
+		return err
 	}
 
 	payload := contract.UpdateFormImageProof{}
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON Payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
-	if err := controller.UpdateAbsentFormExecuseImageProof(absentID, payload.Status); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Failed to update attendance image proof for Absent Form with ID: %d because: %s", absentID, err.Error()),
-		})
+	absentForm, err := h.absent.UpdateAbsentFormExecuseImageProof(c.Request().Context(), absentID, payload.Status, version)
+
+	if err != nil {
+		return err
 	}
 
+	setETag(c, absentForm)
+
 	return c.JSON(http.StatusOK, SuccessUpdateForm{
 		OK:        true,
 		Message:   "Update Success",
@@ -228,40 +207,37 @@ func UpdateAbsentFormExecuseImageProof(c echo.Context) error {
 	})
 }
 
-func UpdateAbsentFormAttendanceImageProof(c echo.Context) error {
+func (h *Handler) UpdateAbsentFormAttendanceImageProof(c echo.Context) error {
 	absentID, err := strconv.Atoi(c.Param("absentID"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "AbsentID must be a valid number.",
-		})
+		return errInvalidParam("absentID")
+	}
+
+	version, err := ifMatchVersion(c)
+
+	if err != nil {
+		return err
 	}
 
 	payload := contract.UpdateFormImageProof{}
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: "Invalid type of JSON Payload received",
-		})
+		return errInvalidPayload()
 	}
 
 	if err := util.Validator.Struct(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, JSONPayloadValidationError{
-			OK:      false,
-			Message: "JSON payload validation error",
-			Details: util.ExtractValidationErrorMsg(err),
-		})
+		return errValidation(err)
 	}
 
-	if err := controller.UpdateAbsentFormAttendanceImageProof(absentID, payload.Status); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorMessage{
-			OK:      false,
-			Message: fmt.Sprintf("Failed to update attendance image proof for Absent Form with ID: %d because: %s", absentID, err.Error()),
-		})
+	absentForm, err := h.absent.UpdateAbsentFormAttendanceImageProof(c.Request().Context(), absentID, payload.Status, version)
+
+	if err != nil {
+		return err
 	}
 
+	setETag(c, absentForm)
+
 	return c.JSON(http.StatusOK, SuccessUpdateForm{
 		OK:        true,
 		Message:   "Update Success",
@@ -269,3 +245,42 @@ func UpdateAbsentFormAttendanceImageProof(c echo.Context) error {
 		Value:     fmt.Sprintf("%t", payload.Status),
 	})
 }
+
+func (h *Handler) UpdateAbsentFormResultVisibility(c echo.Context) error {
+	absentID, err := strconv.Atoi(c.Param("absentID"))
+
+	if err != nil {
+		return errInvalidParam("absentID")
+	}
+
+	version, err := ifMatchVersion(c)
+
+	if err != nil {
+		return err
+	}
+
+	payload := contract.UpdateFormResultVisibility{}
+
+	if err := c.Bind(&payload); err != nil {
+		return errInvalidPayload()
+	}
+
+	if err := util.Validator.Struct(&payload); err != nil {
+		return errValidation(err)
+	}
+
+	absentForm, err := h.absent.UpdateAbsentFormResultVisibility(c.Request().Context(), absentID, payload.Visibility, version)
+
+	if err != nil {
+		return err
+	}
+
+	setETag(c, absentForm)
+
+	return c.JSON(http.StatusOK, SuccessUpdateForm{
+		OK:        true,
+		Message:   "Update Success",
+		FieldName: "resultVisibility",
+		Value:     payload.Visibility,
+	})
+}
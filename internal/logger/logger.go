@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Common field keys, so the same thing is always logged under the same name.
+const (
+	FormIDKey    = "form_id"
+	NPMKey       = "npm"
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+	ErrorKey     = "error"
+)
+
+const (
+	OutputStdout = "stdout"
+	OutputFile   = "file"
+	OutputBoth   = "both"
+)
+
+type Options struct {
+	Format   string // json or logfmt
+	Level    string // debug, info, warn or error
+	Output   string // stdout, file or both
+	FilePath string // used when output is file or both
+	Rotate   RotateOptions
+}
+
+var (
+	mu            sync.RWMutex
+	defaultLogger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+)
+
+// Default returns the logger configured at startup.
+// Before startup it writes logfmt lines to stdout at info level.
+func Default() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return defaultLogger
+}
+
+func SetDefault(l *slog.Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	defaultLogger = l
+}
+
+// New builds a logger from the given options. The returned closer releases the
+// log file, if any, and should be called when the program exits.
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	level, err := ParseLevel(opts.Level)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w, closer, err := openOutput(opts.Output, opts.FilePath, opts.Rotate)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(opts.Format) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), closer, nil
+	case "", "logfmt", "text":
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), closer, nil
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("unknown log format: %s", opts.Format)
+	}
+}
+
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level: %s", level)
+	}
+}
+
+func openOutput(output, filePath string, rotate RotateOptions) (io.Writer, io.Closer, error) {
+	output = strings.ToLower(output)
+
+	if output == "" || output == OutputStdout {
+		return os.Stdout, io.NopCloser(nil), nil
+	}
+
+	if output != OutputFile && output != OutputBoth {
+		return nil, nil, fmt.Errorf("unknown log output: %s", output)
+	}
+
+	if filePath == "" {
+		return nil, nil, fmt.Errorf("log output %s requires a log file name", output)
+	}
+
+	file := OpenFile(filePath, rotate)
+
+	if output == OutputFile {
+		return file, file, nil
+	}
+
+	return io.MultiWriter(os.Stdout, file), file, nil
+}
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx that carries l.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by WithContext.
+func FromContext(ctx context.Context) (*slog.Logger, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	l, ok := ctx.Value(contextKey{}).(*slog.Logger)
+
+	return l, ok
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type RotateOptions struct {
+	MaxSizeMB   int           // rotate when the file grows past this size
+	MaxAgeDays  int           // delete rotated files older than this, 0 keeps them
+	MaxBackups  int           // keep at most this many rotated files, 0 keeps them all
+	Compress    bool          // gzip rotated files
+	RotateEvery time.Duration // also rotate on this interval, 0 disables it
+}
+
+// RotatingFile is a log file that rotates itself by size and interval.
+// It is reopened on SIGHUP, so an external logrotate can move it away.
+type RotatingFile struct {
+	*lumberjack.Logger
+	stop chan struct{}
+}
+
+var (
+	filesMu   sync.Mutex
+	openFiles = map[*RotatingFile]struct{}{}
+	sighup    sync.Once
+)
+
+func OpenFile(path string, opts RotateOptions) *RotatingFile {
+	f := &RotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    opts.MaxSizeMB,
+			MaxAge:     opts.MaxAgeDays,
+			MaxBackups: opts.MaxBackups,
+			Compress:   opts.Compress,
+			LocalTime:  true,
+		},
+		stop: make(chan struct{}),
+	}
+
+	if opts.RotateEvery > 0 {
+		go f.rotateEvery(opts.RotateEvery)
+	}
+
+	filesMu.Lock()
+	openFiles[f] = struct{}{}
+	filesMu.Unlock()
+
+	sighup.Do(reopenOnSIGHUP)
+
+	return f
+}
+
+// Reopen closes the current file. The next write opens it again at its
+// configured path, picking up a new file if it was moved away.
+func (f *RotatingFile) Reopen() error {
+	return f.Logger.Close()
+}
+
+func (f *RotatingFile) Close() error {
+	filesMu.Lock()
+	delete(openFiles, f)
+	filesMu.Unlock()
+
+	select {
+	case <-f.stop:
+	default:
+		close(f.stop)
+	}
+
+	return f.Logger.Close()
+}
+
+func (f *RotatingFile) rotateEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := f.Rotate(); err != nil {
+				Default().Error("Failed to rotate log file", "file", f.Filename, ErrorKey, err)
+			}
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+func reopenOnSIGHUP() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for range signals {
+			filesMu.Lock()
+
+			for f := range openFiles {
+				if err := f.Reopen(); err != nil {
+					Default().Error("Failed to reopen log file", "file", f.Filename, ErrorKey, err)
+				}
+			}
+
+			filesMu.Unlock()
+
+			Default().Info("Log files reopened after SIGHUP")
+		}
+	}()
+}
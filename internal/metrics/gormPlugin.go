@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// GormPlugin measures the duration of every query made through GORM.
+// Register it with db.Use(metrics.GormPlugin{}).
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "himatro:metrics"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, hook := range hooks {
+		if err := hook.before(p.Name()+":before_"+hook.operation, before); err != nil {
+			return err
+		}
+
+		if err := hook.after(p.Name()+":after_"+hook.operation, after(hook.operation)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+
+		if !ok {
+			return
+		}
+
+		start, ok := value.(time.Time)
+
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+
+		if table == "" {
+			table = "unknown"
+		}
+
+		dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "himatro_api"
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests per route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	loginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_attempts_total",
+		Help:      "Login attempts by result.",
+	}, []string{"result"})
+
+	attendanceSubmissions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "attendance_submissions_total",
+		Help:      "Attendance submissions by absent form and status.",
+	}, []string{"form_id", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of database queries by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+)
+
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+)
+
+const (
+	SubmissionRejected = "rejected"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		loginAttempts,
+		attendanceSubmissions,
+		dbQueryDuration,
+	)
+}
+
+// Middleware records latency and status code of every request, labeled with
+// the route template instead of the raw URI to keep the label set small. An
+// error is written first to know its status, then still returned, so outer
+// middlewares see it too. The error handler doesn't write a committed response
+// again.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			if err != nil {
+				c.Error(err)
+			}
+
+			route := c.Path()
+
+			if route == "" {
+				route = "unmatched"
+			}
+
+			httpRequestDuration.
+				WithLabelValues(c.Request().Method, route, strconv.Itoa(c.Response().Status)).
+				Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+func ObserveLogin(result string) {
+	loginAttempts.WithLabelValues(result).Inc()
+}
+
+// ObserveAttendanceSubmission counts a submission. Status is the keterangan
+// sent by the attendant, or SubmissionRejected when it was not saved. The
+// form_id label grows with the number of forms, not with the traffic.
+func ObserveAttendanceSubmission(formID int, status string) {
+	attendanceSubmissions.WithLabelValues(strconv.Itoa(formID), status).Inc()
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// Deprecated marks a route as deprecated (RFC 9745) and announces when it will
+// be removed (RFC 8594). The Link header points to the same path under successorPrefix.
+func Deprecated(deprecatedAt, sunset time.Time, successorPrefix string) echo.MiddlewareFunc {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+
+			header.Set(HeaderDeprecation, deprecation)
+			header.Set(HeaderSunset, sunsetDate)
+			header.Add(HeaderLink, fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successorPrefix, c.Request().URL.Path))
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/config"
+	"himatro-api/internal/controller"
+	"io"
+	"net"
+	"net/http"
+	"unicode"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// idempotentResponseHeaders are the response headers replayed with a stored
+// response. Set-Cookie is never stored: the cookie of a member's update token
+// must only reach the request that created it.
+var idempotentResponseHeaders = []string{
+	echo.HeaderContentType,
+	echo.HeaderLocation,
+	"ETag",
+}
+
+// Idempotency makes a route safe to retry. The response of a request sent with an
+// Idempotency-Key header is stored, and a retry with the same key and body gets the
+// stored response back instead of running the handler again. Requests without
+// the header are handled as usual. Server errors are not stored, so they can be retried.
+// Keys are scoped to the route and the caller, see idempotencyScope.
+func Idempotency(keys *controller.IdempotencyController) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+
+			if key == "" {
+				return next(c)
+			}
+
+			if !isValidIdempotencyKey(key) {
+				return apperr.BadRequest(apperr.CodeInvalidIdempotencyKey, "Idempotency-Key must be at most %d printable characters", maxIdempotencyKeyLength)
+			}
+
+			body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxIdempotentRequestBytes+1))
+
+			if err != nil {
+				return apperr.BadRequest(apperr.CodeInvalidPayload, "failed to read request body")
+			}
+
+			if len(body) > maxIdempotentRequestBytes {
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := c.Request().Context()
+			scope := idempotencyScope(c)
+			hash := sha256.Sum256(body)
+
+			stored, err := keys.ReserveIdempotencyKey(ctx, key, scope, hex.EncodeToString(hash[:]), config.Get().Idempotency.TTL)
+
+			if err != nil {
+				return err
+			}
+
+			if stored != nil {
+				header := keys.IdempotentResponseHeader(ctx, stored)
+
+				for _, name := range idempotentResponseHeaders {
+					for _, value := range header.Values(name) {
+						c.Response().Header().Add(name, value)
+					}
+				}
+
+				c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				c.Response().WriteHeader(stored.StatusCode)
+				_, err := c.Response().Write(stored.Body)
+
+				return err
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			if c.Response().Status >= http.StatusInternalServerError {
+				return keys.ReleaseIdempotencyKey(ctx, key, scope)
+			}
+
+			header := http.Header{}
+
+			for _, name := range idempotentResponseHeaders {
+				for _, value := range c.Response().Header().Values(name) {
+					header.Add(name, value)
+				}
+			}
+
+			if err := keys.CompleteIdempotencyKey(ctx, key, scope, c.Response().Status, header, recorder.body.Bytes()); err != nil {
+				// The response is already sent. Free the key rather than leave it
+				// reserved, so a retry runs again instead of failing until it expires.
+				return keys.ReleaseIdempotencyKey(ctx, key, scope)
+			}
+
+			return nil
+		}
+	}
+}
+
+// idempotencyScope is the route and the caller a key belongs to, so a key can't
+// replay the response of someone else. The caller is the Authorization header
+// when there is one, otherwise the client IP.
+func idempotencyScope(c echo.Context) string {
+	caller := "ip:" + c.RealIP()
+
+	if authorization := c.Request().Header.Get(echo.HeaderAuthorization); authorization != "" {
+		hash := sha256.Sum256([]byte(authorization))
+		caller = "auth:" + hex.EncodeToString(hash[:])
+	}
+
+	return c.Request().Method + " " + c.Request().URL.Path + " " + caller
+}
+
+func isValidIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+
+	for _, r := range key {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// responseRecorder copies everything written to the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
@@ -2,19 +2,31 @@ package middleware
 
 import (
 	"himatro-api/internal/config"
+	"himatro-api/internal/logger"
+	"io"
 	"os"
 
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
 )
 
-var reqLogName = os.Getenv("LOG_FILE_NAME")
-var reqLogFile, _ = os.OpenFile(reqLogName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+// reqLogFormat is the echo default format, with the request ID logged under
+// the same key as the application logs.
+var reqLogFormat = `{"time":"${time_rfc3339_nano}","request_id":"${id}","remote_ip":"${remote_ip}",` +
+	`"host":"${host}","method":"${method}","uri":"${uri}","user_agent":"${user_agent}",` +
+	`"status":${status},"error":"${error}","latency":${latency},"latency_human":"${latency_human}"` +
+	`,"bytes_in":${bytes_in},"bytes_out":${bytes_out}}` + "\n"
 
 func RequestLogger() echo.MiddlewareFunc {
+	var output io.Writer = os.Stdout
+
+	if name := config.Get().Log.FileName; name != "" {
+		output = logger.OpenFile(name, config.Get().Log.RotateOptions())
+	}
+
 	return echoMiddleware.LoggerWithConfig(echoMiddleware.LoggerConfig{
 		Skipper: func(c echo.Context) bool {
-			for _, route := range config.GetSkippedLogRoutes() {
+			for _, route := range config.Get().Log.SkippedRoutes {
 				if route == c.Request().RequestURI {
 					return true
 				}
@@ -22,6 +34,7 @@ func RequestLogger() echo.MiddlewareFunc {
 
 			return false
 		},
-		Output: reqLogFile,
+		Format: reqLogFormat,
+		Output: output,
 	})
 }
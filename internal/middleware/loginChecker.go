@@ -1,11 +1,22 @@
 package middleware
 
 import (
-	"himatro-api/internal/config"
+	"himatro-api/internal/apperr"
+	"himatro-api/internal/auth"
+	"himatro-api/internal/clock"
 
+	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-var RequireLogin = middleware.JWTWithConfig(middleware.JWTConfig{
-	SigningKey: []byte(config.JWTSigningKey()),
-})
+// RequireLogin rejects requests without a valid login token. Expiry is checked against clk.
+func RequireLogin(clk clock.Clock) echo.MiddlewareFunc {
+	return middleware.JWTWithConfig(middleware.JWTConfig{
+		ParseTokenFunc: func(token string, c echo.Context) (interface{}, error) {
+			return auth.ParseLoginToken(clk, token)
+		},
+		ErrorHandlerWithContext: func(err error, c echo.Context) error {
+			return apperr.Unauthorized(apperr.CodeUnauthorized, "login token is missing or invalid")
+		},
+	})
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"himatro-api/internal/config"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+var RequireMetricsKey = echoMiddleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+	expected := config.Get().Metrics.APIKey
+
+	return expected != "" && subtle.ConstantTimeCompare([]byte(key), []byte(expected)) == 1, nil
+})
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"himatro-api/internal/logger"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDContextKey = "request_id"
+
+const maxRequestIDLength = 128
+
+// RequestID accepts the X-Request-ID header sent by the client (or Nginx), or
+// generates a new one. The ID is returned in the response header and attached
+// to the logger stored in the request context.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := req.Header.Get(echo.HeaderXRequestID)
+
+			if !isValidRequestID(id) {
+				id = generateRequestID()
+				req.Header.Set(echo.HeaderXRequestID, id)
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.Set(RequestIDContextKey, id)
+
+			l := logger.Default().With(logger.RequestIDKey, id)
+			span := trace.SpanFromContext(req.Context())
+
+			if span.SpanContext().IsValid() {
+				span.SetAttributes(attribute.String(logger.RequestIDKey, id))
+				l = l.With(logger.TraceIDKey, span.SpanContext().TraceID().String())
+			}
+
+			c.SetRequest(req.WithContext(logger.WithContext(req.Context(), l)))
+
+			return next(c)
+		}
+	}
+}
+
+// isValidRequestID only accepts letters, digits, '.', '_' and '-'. The ID is
+// written unescaped in the access log, which is built as JSON by hand.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func generateRequestID() string {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		logger.Default().Error("Failed to generate request ID", logger.ErrorKey, err)
+		return "unknown"
+	}
+
+	return hex.EncodeToString(b)
+}
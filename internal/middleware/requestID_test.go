@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidRequestID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"nginx-1.2_3", true},
+		{strings.Repeat("a", maxRequestIDLength), true},
+		{strings.Repeat("a", maxRequestIDLength+1), false},
+		{"", false},
+		{`a"b`, false},
+		{`a\b`, false},
+		{"a b", false},
+		{"a\nb", false},
+		{"a{b}", false},
+		{"ä", false},
+	}
+
+	for _, tt := range tests {
+		if got := isValidRequestID(tt.id); got != tt.valid {
+			t.Errorf("isValidRequestID(%q) = %v, want %v", tt.id, got, tt.valid)
+		}
+	}
+}
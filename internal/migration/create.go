@@ -0,0 +1,111 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var nameSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty up and down file for a new migration in every driver
+// directory of dir, numbered after the newest migration of any driver, and
+// returns their paths.
+func Create(dir string, name string) ([]string, error) {
+	name = strings.Trim(nameSeparators.ReplaceAllString(strings.ToLower(name), "_"), "_")
+
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	driverDirs := []string{}
+	var latest uint64
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		driverDir := filepath.Join(dir, entry.Name())
+		driverDirs = append(driverDirs, driverDir)
+
+		version, err := latestVersion(driverDir)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if version > latest {
+			latest = version
+		}
+	}
+
+	if len(driverDirs) == 0 {
+		return nil, fmt.Errorf("%s has no driver directory", dir)
+	}
+
+	base := fmt.Sprintf("%04d_%s", latest+1, name)
+	created := []string{}
+
+	for _, driverDir := range driverDirs {
+		up := filepath.Join(driverDir, base+".up.sql")
+		down := filepath.Join(driverDir, base+".down.sql")
+
+		if err := os.WriteFile(up, []byte(fmt.Sprintf("-- %s\n", name)), 0644); err != nil {
+			removeAll(created)
+			return nil, err
+		}
+
+		created = append(created, up)
+
+		if err := os.WriteFile(down, []byte(fmt.Sprintf("-- revert %s\n", name)), 0644); err != nil {
+			removeAll(created)
+			return nil, err
+		}
+
+		created = append(created, down)
+	}
+
+	return created, nil
+}
+
+func latestVersion(dir string) (uint64, error) {
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint64
+
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+
+		if err == nil && version > latest {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
+
+func removeAll(paths []string) {
+	for _, path := range paths {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,158 @@
+// Package migration applies the numbered SQL migrations embedded in the binary
+// and records them in the schema_migrations table. Every database driver has its
+// own directory of migrations, numbered in step with the others.
+package migration
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql/*/*.sql
+var embedded embed.FS
+
+// Dir is where the migration directories of every driver live in the source
+// tree, relative to the repository root.
+const Dir = "internal/migration/sql"
+
+// noTransaction is the first line of a migration file that must not run in a
+// transaction, e.g. one using CREATE INDEX CONCURRENTLY.
+const noTransaction = "-- migrate:no-transaction"
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version uint
+	Name    string
+
+	Up   string
+	Down string
+
+	UpInTransaction   bool
+	DownInTransaction bool
+}
+
+// Embedded returns the migrations of driver built into the binary, oldest first.
+func Embedded(driver string) ([]Migration, error) {
+	if _, err := fs.Stat(embedded, path.Join("sql", driver)); err != nil {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+
+	sub, err := fs.Sub(embedded, path.Join("sql", driver))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Load(sub)
+}
+
+// Latest returns the version of the newest migration of driver built into the
+// binary, which is the version a migrated database is at.
+func Latest(driver string) (uint, error) {
+	migrations, err := Embedded(driver)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(migrations) == 0 {
+		return 0, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// Load reads the migrations in the root of fsys, oldest first. Every version
+// needs both an up and a down file.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*Migration{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s must be named <version>_<name>.<up|down>.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s has an invalid version: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+			m.UpInTransaction = !hasNoTransactionMarker(m.Up)
+		} else {
+			m.Down = string(content)
+			m.DownInTransaction = !hasNoTransactionMarker(m.Down)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func hasNoTransactionMarker(sql string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(sql))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		return line == noTransaction
+	}
+
+	return false
+}
@@ -0,0 +1,195 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"himatro-api/internal/clock"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// appliedMigration is a row of schema_migrations.
+type appliedMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	clock      clock.Clock
+}
+
+// New returns a Migrator that applies migrations, oldest first, to db.
+func New(db *gorm.DB, migrations []Migration, clk clock.Clock) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		clock:      clk,
+	}
+}
+
+// Up applies every pending migration and returns them. It stops at the first
+// failure; the migrations applied before it stay applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		record := func(tx *gorm.DB) error {
+			return tx.Create(&appliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: m.clock.Now(),
+			}).Error
+		}
+
+		if err := m.run(ctx, migration.Up, migration.UpInTransaction, record); err != nil {
+			return done, fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down rolls back the last n applied migrations, newest first, and returns them.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[uint]Migration, len(m.migrations))
+
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	versions := make([]uint, 0, len(applied))
+
+	for version := range applied {
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+
+	done := []Migration{}
+
+	for _, version := range versions {
+		if len(done) == n {
+			break
+		}
+
+		migration, ok := known[version]
+
+		if !ok {
+			return done, fmt.Errorf("migration %d is applied but this binary has no file for it, roll it back with the version that added it", version)
+		}
+
+		record := func(tx *gorm.DB) error {
+			return tx.Delete(&appliedMigration{}, migration.Version).Error
+		}
+
+		if err := m.run(ctx, migration.Down, migration.DownInTransaction, record); err != nil {
+			return done, fmt.Errorf("rollback of migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Status lists every known migration and whether it is applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]Status, 0, len(m.migrations))
+
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+
+		status = append(status, Status{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: record.AppliedAt,
+		})
+	}
+
+	return status, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[uint]appliedMigration, error) {
+	db := m.db.WithContext(ctx)
+
+	if err := db.AutoMigrate(&appliedMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	records := []appliedMigration{}
+
+	if err := db.Order("version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	applied := make(map[uint]appliedMigration, len(records))
+
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return applied, nil
+}
+
+// run executes sql and record in one transaction, or one after the other when
+// the migration opted out of transactions.
+func (m *Migrator) run(ctx context.Context, sql string, inTransaction bool, record func(tx *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+
+	if !inTransaction {
+		if err := db.Exec(sql).Error; err != nil {
+			return err
+		}
+
+		return record(db)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+
+		return record(tx)
+	})
+}
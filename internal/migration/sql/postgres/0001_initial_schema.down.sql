@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "idempotency_keys";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "absent_lists";
+DROP TABLE IF EXISTS "form_absensis";
+DROP TABLE IF EXISTS "pengurus";
+DROP TABLE IF EXISTS "departemens";
+DROP TABLE IF EXISTS "jabatans";
+DROP TABLE IF EXISTS "anggota_biasas";
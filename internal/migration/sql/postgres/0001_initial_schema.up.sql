@@ -0,0 +1,92 @@
+-- The schema created by the AutoMigrate based migrate command. Every statement
+-- is IF NOT EXISTS, so databases created by it are adopted as they are. Columns
+-- added to existing tables after the first release are added here too, because
+-- a database last migrated by that release doesn't have them yet.
+
+CREATE TABLE IF NOT EXISTS "anggota_biasas" (
+	"npm" text,
+	"nama" text,
+	PRIMARY KEY ("npm")
+);
+
+CREATE TABLE IF NOT EXISTS "jabatans" (
+	"id" bigserial,
+	"privilege_level" bigint NOT NULL,
+	"name" text NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "departemens" (
+	"id" bigserial,
+	"nama" text,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "pengurus" (
+	"npm" text,
+	"departemen_id" bigint,
+	"jabatan_id" bigint,
+	PRIMARY KEY ("npm"),
+	CONSTRAINT "fk_pengurus_departemen" FOREIGN KEY ("departemen_id") REFERENCES "departemens" ("id"),
+	CONSTRAINT "fk_pengurus_jabatan" FOREIGN KEY ("jabatan_id") REFERENCES "jabatans" ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "form_absensis" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"title" text NOT NULL,
+	"participant" bigint NOT NULL,
+	"start_at" timestamptz NOT NULL,
+	"finish_at" timestamptz NOT NULL,
+	"require_attendance_image_proof" boolean NOT NULL,
+	"require_execuse_image_proof" boolean NOT NULL,
+	"result_visibility" text NOT NULL DEFAULT 'public',
+	"version" bigint NOT NULL DEFAULT 1,
+	PRIMARY KEY ("id")
+);
+
+ALTER TABLE "form_absensis" ADD COLUMN IF NOT EXISTS "result_visibility" text NOT NULL DEFAULT 'public';
+ALTER TABLE "form_absensis" ADD COLUMN IF NOT EXISTS "version" bigint NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS "idx_form_absensis_deleted_at" ON "form_absensis" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "absent_lists" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"form_absensi_id" bigint,
+	"npm" text,
+	"keterangan" text DEFAULT '?',
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_absent_lists_form_absensi" FOREIGN KEY ("form_absensi_id") REFERENCES "form_absensis" ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "users" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"npm" text,
+	"password" text,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "idempotency_keys" (
+	"id" bigserial,
+	"idempotency_key" text NOT NULL,
+	"scope" text NOT NULL,
+	"request_hash" text NOT NULL,
+	"completed" boolean NOT NULL DEFAULT false,
+	"status_code" bigint,
+	"header" text,
+	"body" bytea,
+	"created_at" timestamptz,
+	"expires_at" timestamptz NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_keys_scope_key" ON "idempotency_keys" ("idempotency_key", "scope");
@@ -0,0 +1,33 @@
+-- Removed duplicates, soft deleted rows and the placeholder members added by the
+-- up migration are not restored.
+
+DROP INDEX IF EXISTS "idx_pengurus_departemen_id";
+DROP INDEX IF EXISTS "idx_users_npm";
+DROP INDEX IF EXISTS "idx_absent_lists_npm";
+DROP INDEX IF EXISTS "idx_absent_lists_form_npm";
+
+ALTER TABLE "pengurus" DROP CONSTRAINT IF EXISTS "fk_pengurus_jabatan";
+ALTER TABLE "pengurus" DROP CONSTRAINT IF EXISTS "fk_pengurus_departemen";
+ALTER TABLE "pengurus" DROP CONSTRAINT IF EXISTS "fk_pengurus_anggota_biasa";
+ALTER TABLE "pengurus"
+	ADD CONSTRAINT "fk_pengurus_departemen" FOREIGN KEY ("departemen_id") REFERENCES "departemens" ("id"),
+	ADD CONSTRAINT "fk_pengurus_jabatan" FOREIGN KEY ("jabatan_id") REFERENCES "jabatans" ("id");
+
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "fk_users_anggota_biasa";
+
+ALTER TABLE "absent_lists" DROP CONSTRAINT IF EXISTS "fk_absent_lists_anggota_biasa";
+ALTER TABLE "absent_lists" DROP CONSTRAINT IF EXISTS "fk_absent_lists_form_absensi";
+ALTER TABLE "absent_lists"
+	ADD CONSTRAINT "fk_absent_lists_form_absensi" FOREIGN KEY ("form_absensi_id") REFERENCES "form_absensis" ("id");
+
+ALTER TABLE "users"
+	ALTER COLUMN "npm" DROP NOT NULL,
+	ALTER COLUMN "password" DROP NOT NULL;
+
+ALTER TABLE "absent_lists"
+	ALTER COLUMN "form_absensi_id" DROP NOT NULL,
+	ALTER COLUMN "npm" DROP NOT NULL,
+	ALTER COLUMN "keterangan" DROP NOT NULL;
+
+ALTER TABLE "users" ADD COLUMN "deleted_at" timestamptz;
+ALTER TABLE "absent_lists" ADD COLUMN "deleted_at" timestamptz;
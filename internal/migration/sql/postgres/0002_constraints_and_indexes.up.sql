@@ -0,0 +1,86 @@
+-- Enforce one absent list row per member and form and one login per NPM, and
+-- constrain every npm column to anggota_biasas. Existing data is cleaned up
+-- first so the constraints can be created.
+
+-- Soft deleted rows were never returned, drop them instead of reviving them.
+DELETE FROM "absent_lists" WHERE "deleted_at" IS NOT NULL;
+DELETE FROM "users" WHERE "deleted_at" IS NOT NULL;
+
+DELETE FROM "absent_lists"
+WHERE "form_absensi_id" IS NULL
+	OR "npm" IS NULL
+	OR "form_absensi_id" NOT IN (SELECT "id" FROM "form_absensis");
+
+UPDATE "absent_lists" SET "keterangan" = '?' WHERE "keterangan" IS NULL;
+
+-- Keep the filled attendance, then the latest one, then the oldest row.
+DELETE FROM "absent_lists" WHERE "id" IN (
+	SELECT "id" FROM (
+		SELECT "id", row_number() OVER (
+			PARTITION BY "form_absensi_id", "npm"
+			ORDER BY
+				("keterangan" = '?'),
+				COALESCE("updated_at", "created_at") DESC NULLS LAST,
+				"id"
+		) AS "rank"
+		FROM "absent_lists"
+	) AS "ranked"
+	WHERE "rank" > 1
+);
+
+DELETE FROM "users" WHERE "npm" IS NULL OR "password" IS NULL;
+
+DELETE FROM "users" WHERE "id" IN (
+	SELECT "id" FROM (
+		SELECT "id", row_number() OVER (PARTITION BY "npm" ORDER BY "id") AS "rank"
+		FROM "users"
+	) AS "ranked"
+	WHERE "rank" > 1
+);
+
+-- Rows pointing at an unknown NPM keep their data under a placeholder member.
+INSERT INTO "anggota_biasas" ("npm", "nama")
+SELECT DISTINCT "npm", "npm" FROM (
+	SELECT "npm" FROM "absent_lists"
+	UNION SELECT "npm" FROM "users"
+	UNION SELECT "npm" FROM "pengurus"
+) AS "referenced"
+WHERE "npm" NOT IN (SELECT "npm" FROM "anggota_biasas");
+
+ALTER TABLE "absent_lists" DROP COLUMN "deleted_at";
+ALTER TABLE "users" DROP COLUMN "deleted_at";
+
+ALTER TABLE "absent_lists"
+	ALTER COLUMN "form_absensi_id" SET NOT NULL,
+	ALTER COLUMN "npm" SET NOT NULL,
+	ALTER COLUMN "keterangan" SET NOT NULL;
+
+ALTER TABLE "users"
+	ALTER COLUMN "npm" SET NOT NULL,
+	ALTER COLUMN "password" SET NOT NULL;
+
+ALTER TABLE "absent_lists" DROP CONSTRAINT IF EXISTS "fk_absent_lists_form_absensi";
+ALTER TABLE "absent_lists"
+	ADD CONSTRAINT "fk_absent_lists_form_absensi" FOREIGN KEY ("form_absensi_id")
+		REFERENCES "form_absensis" ("id") ON DELETE CASCADE,
+	ADD CONSTRAINT "fk_absent_lists_anggota_biasa" FOREIGN KEY ("npm")
+		REFERENCES "anggota_biasas" ("npm") ON UPDATE CASCADE ON DELETE RESTRICT;
+
+ALTER TABLE "users"
+	ADD CONSTRAINT "fk_users_anggota_biasa" FOREIGN KEY ("npm")
+		REFERENCES "anggota_biasas" ("npm") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "pengurus" DROP CONSTRAINT IF EXISTS "fk_pengurus_departemen";
+ALTER TABLE "pengurus" DROP CONSTRAINT IF EXISTS "fk_pengurus_jabatan";
+ALTER TABLE "pengurus"
+	ADD CONSTRAINT "fk_pengurus_anggota_biasa" FOREIGN KEY ("npm")
+		REFERENCES "anggota_biasas" ("npm") ON UPDATE CASCADE ON DELETE CASCADE,
+	ADD CONSTRAINT "fk_pengurus_departemen" FOREIGN KEY ("departemen_id")
+		REFERENCES "departemens" ("id") ON UPDATE CASCADE ON DELETE RESTRICT,
+	ADD CONSTRAINT "fk_pengurus_jabatan" FOREIGN KEY ("jabatan_id")
+		REFERENCES "jabatans" ("id") ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE UNIQUE INDEX "idx_absent_lists_form_npm" ON "absent_lists" ("form_absensi_id", "npm");
+CREATE INDEX "idx_absent_lists_npm" ON "absent_lists" ("npm");
+CREATE UNIQUE INDEX "idx_users_npm" ON "users" ("npm");
+CREATE INDEX "idx_pengurus_departemen_id" ON "pengurus" ("departemen_id");
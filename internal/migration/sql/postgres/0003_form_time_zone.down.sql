@@ -0,0 +1 @@
+ALTER TABLE "form_absensis" DROP COLUMN IF EXISTS "time_zone";
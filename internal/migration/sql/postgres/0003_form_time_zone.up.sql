@@ -0,0 +1,3 @@
+-- The IANA time zone a form's schedule is entered and shown in. Existing forms
+-- keep an empty zone, which means the server's TZ.
+ALTER TABLE "form_absensis" ADD COLUMN IF NOT EXISTS "time_zone" text NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_absent_lists_deleted_at";
+ALTER TABLE "absent_lists" DROP COLUMN IF EXISTS "deleted_at";
@@ -0,0 +1,10 @@
+-- Absent lists are archived with their form. Rows of forms that are already
+-- archived take the archive time of their form.
+ALTER TABLE "absent_lists" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+
+UPDATE "absent_lists" SET "deleted_at" = "form_absensis"."deleted_at"
+FROM "form_absensis"
+WHERE "form_absensis"."id" = "absent_lists"."form_absensi_id"
+	AND "form_absensis"."deleted_at" IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS "idx_absent_lists_deleted_at" ON "absent_lists" ("deleted_at");
@@ -0,0 +1,104 @@
+-- SQLite databases are only used for local development and tests, so they start
+-- with the constraints the Postgres schema gets in 0002. SQLite support came
+-- with the migrations, so there is no AutoMigrate database to adopt: the tables
+-- are created without IF NOT EXISTS, and a database that already has them makes
+-- this migration fail instead of silently missing columns.
+
+CREATE TABLE "anggota_biasas" (
+	"npm" text,
+	"nama" text,
+	PRIMARY KEY ("npm")
+);
+
+CREATE TABLE "jabatans" (
+	"id" integer,
+	"privilege_level" integer NOT NULL,
+	"name" text NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE "departemens" (
+	"id" integer,
+	"nama" text,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE "pengurus" (
+	"npm" text,
+	"departemen_id" integer,
+	"jabatan_id" integer,
+	PRIMARY KEY ("npm"),
+	CONSTRAINT "fk_pengurus_anggota_biasa" FOREIGN KEY ("npm")
+		REFERENCES "anggota_biasas" ("npm") ON UPDATE CASCADE ON DELETE CASCADE,
+	CONSTRAINT "fk_pengurus_departemen" FOREIGN KEY ("departemen_id")
+		REFERENCES "departemens" ("id") ON UPDATE CASCADE ON DELETE RESTRICT,
+	CONSTRAINT "fk_pengurus_jabatan" FOREIGN KEY ("jabatan_id")
+		REFERENCES "jabatans" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE INDEX "idx_pengurus_departemen_id" ON "pengurus" ("departemen_id");
+
+CREATE TABLE "form_absensis" (
+	"id" integer,
+	"created_at" datetime,
+	"updated_at" datetime,
+	"deleted_at" datetime,
+	"title" text NOT NULL,
+	"participant" integer NOT NULL,
+	"start_at" datetime NOT NULL,
+	"finish_at" datetime NOT NULL,
+	"require_attendance_image_proof" numeric NOT NULL,
+	"require_execuse_image_proof" numeric NOT NULL,
+	"result_visibility" text NOT NULL DEFAULT 'public',
+	"version" integer NOT NULL DEFAULT 1,
+	PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_form_absensis_deleted_at" ON "form_absensis" ("deleted_at");
+
+CREATE TABLE "absent_lists" (
+	"id" integer,
+	"created_at" datetime,
+	"updated_at" datetime,
+	"form_absensi_id" integer NOT NULL,
+	"npm" text NOT NULL,
+	"keterangan" text NOT NULL DEFAULT '?',
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_absent_lists_form_absensi" FOREIGN KEY ("form_absensi_id")
+		REFERENCES "form_absensis" ("id") ON DELETE CASCADE,
+	CONSTRAINT "fk_absent_lists_anggota_biasa" FOREIGN KEY ("npm")
+		REFERENCES "anggota_biasas" ("npm") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX "idx_absent_lists_form_npm" ON "absent_lists" ("form_absensi_id", "npm");
+CREATE INDEX "idx_absent_lists_npm" ON "absent_lists" ("npm");
+
+CREATE TABLE "users" (
+	"id" integer,
+	"created_at" datetime,
+	"updated_at" datetime,
+	"npm" text NOT NULL,
+	"password" text NOT NULL,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_users_anggota_biasa" FOREIGN KEY ("npm")
+		REFERENCES "anggota_biasas" ("npm") ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX "idx_users_npm" ON "users" ("npm");
+
+CREATE TABLE "idempotency_keys" (
+	"id" integer,
+	"idempotency_key" text NOT NULL,
+	"scope" text NOT NULL,
+	"request_hash" text NOT NULL,
+	"completed" numeric NOT NULL DEFAULT false,
+	"status_code" integer,
+	"header" text,
+	"body" blob,
+	"created_at" datetime,
+	"expires_at" datetime NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
+CREATE UNIQUE INDEX "idx_idempotency_keys_scope_key" ON "idempotency_keys" ("idempotency_key", "scope");
@@ -0,0 +1,2 @@
+-- Nothing to do, see the up migration.
+SELECT 1;
@@ -0,0 +1,3 @@
+-- Nothing to do: the SQLite schema starts with these constraints and indexes in
+-- 0001. The file keeps the versions of both drivers in step.
+SELECT 1;
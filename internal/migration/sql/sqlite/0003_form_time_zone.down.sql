@@ -0,0 +1 @@
+ALTER TABLE "form_absensis" DROP COLUMN "time_zone";
@@ -3,16 +3,26 @@ package models
 import (
 	"time"
 
-	"github.com/jinzhu/gorm"
+	"gorm.io/gorm"
 )
 
+// AbsentList is the attendance of one member on one form. DeletedAt is only set
+// when its form is archived, and cleared again on a restore. Regenerating the
+// participants of a form deletes its rows for real, because a soft deleted row
+// would still hold the unique (form_absensi_id, npm) index.
+//
+// GORM reads AnggotaBiasa as a has-one because both structs have an NPM field, so
+// the npm foreign key (ON DELETE RESTRICT) is only declared in the migrations.
 type AbsentList struct {
-	gorm.Model
-	FormAbsensiID uint
-	NPM           string
-	Keterangan    string       `gorm:"default:'?'"`
-	AnggotaBiasa  AnggotaBiasa `gorm:"foreignKey:NPM"`
-	FormAbsensi   FormAbsensi  `gorm:"foreignKey:FormAbsensiID"`
+	ID            uint `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	FormAbsensiID uint           `gorm:"not null;uniqueIndex:idx_absent_lists_form_npm"`
+	NPM           string         `gorm:"not null;uniqueIndex:idx_absent_lists_form_npm;index"`
+	Keterangan    string         `gorm:"not null;default:'?'"`
+	AnggotaBiasa  AnggotaBiasa   `gorm:"foreignKey:NPM"`
+	FormAbsensi   FormAbsensi    `gorm:"foreignKey:FormAbsensiID;constraint:OnDelete:CASCADE"`
 }
 
 type ReturnedAbsentList struct {
@@ -1,11 +1,21 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+const (
+	ResultVisibilityPublic  = "public"
+	ResultVisibilityMembers = "members"
+	ResultVisibilityAdmins  = "admins"
+	ResultVisibilityMasked  = "masked"
+)
+
+// FormAbsensi is an absent form. The DeletedAt of gorm.Model marks an archived
+// form, which GORM leaves out of every query unless it is Unscoped.
 type FormAbsensi struct {
 	gorm.Model
 
@@ -15,20 +25,52 @@ type FormAbsensi struct {
 	FinishAt                    time.Time `gorm:"not null"`
 	RequireAttendanceImageProof bool      `gorm:"not null"`
 	RequireExecuseImageProof    bool      `gorm:"not null"`
+	ResultVisibility            string    `gorm:"not null;default:'public'"`
+
+	// TimeZone is the IANA zone the schedule is entered and shown in, empty
+	// means the server's TZ.
+	TimeZone string `gorm:"not null;default:''"`
+
+	// Version is incremented on every update, it is the ETag of the form.
+	Version uint `gorm:"not null;default:1"`
 }
 
 type ReturnedFormAbsentDetails struct {
-	FormID                      uint      `json:"form_id"`
-	Title                       string    `json:"title"`
-	CreatedAt                   time.Time `json:"created_at"`
-	UpdatedAt                   time.Time `json:"updated_at"`
-	ParticipantCode             int       `json:"participant_code"`
-	StartAt                     time.Time `json:"start_at"`
-	FinishAt                    time.Time `json:"finish_at"`
-	RequireAttendanceImageProof bool      `json:"require_attendance_image_proof"`
-	RequireExecuseImageProof    bool      `json:"require_execuse_image_proof"`
-	TotalParticipant            int       `json:"total_participant"`
-	Hadir                       int       `json:"hadir"`
-	Izin                        int       `json:"izin"`
-	TanpaKeterangan             int       `json:"tanpa_keterangan"`
+	FormID                      uint       `json:"form_id"`
+	Title                       string     `json:"title"`
+	CreatedAt                   time.Time  `json:"created_at"`
+	UpdatedAt                   time.Time  `json:"updated_at"`
+	ParticipantCode             int        `json:"participant_code"`
+	StartAt                     time.Time  `json:"start_at"`
+	FinishAt                    time.Time  `json:"finish_at"`
+	RequireAttendanceImageProof bool       `json:"require_attendance_image_proof"`
+	RequireExecuseImageProof    bool       `json:"require_execuse_image_proof"`
+	ResultVisibility            string     `json:"result_visibility"`
+	TimeZone                    string     `json:"time_zone"`
+	ArchivedAt                  *time.Time `json:"archived_at,omitempty"`
+	TotalParticipant            int        `json:"total_participant"`
+	Hadir                       int        `json:"hadir"`
+	Izin                        int        `json:"izin"`
+	TanpaKeterangan             int        `json:"tanpa_keterangan"`
+}
+
+type ReturnedAbsentForm struct {
+	FormID                      uint       `json:"formID"`
+	Title                       string     `json:"title"`
+	Participant                 string     `json:"participant"`
+	StartAt                     time.Time  `json:"startAt"`
+	FinishAt                    time.Time  `json:"finishAt"`
+	RequireAttendanceImageProof bool       `json:"requireAttendanceImageProof"`
+	RequireExecuseImageProof    bool       `json:"requireExecuseImageProof"`
+	ResultVisibility            string     `json:"resultVisibility"`
+	TimeZone                    string     `json:"timeZone"`
+	ArchivedAt                  *time.Time `json:"archivedAt,omitempty"`
+	Version                     uint       `json:"version"`
+	CreatedAt                   time.Time  `json:"createdAt"`
+	UpdatedAt                   time.Time  `json:"updatedAt"`
+}
+
+// ETag is the entity tag of the form, used with If-Match to detect lost updates.
+func (f ReturnedAbsentForm) ETag() string {
+	return fmt.Sprintf("\"%d\"", f.Version)
 }
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyKey stores the response of a request sent with an Idempotency-Key
+// header, so a retry of the same request gets the same response back.
+type IdempotencyKey struct {
+	ID          uint   `gorm:"primarykey"`
+	Key         string `gorm:"column:idempotency_key;not null;uniqueIndex:idx_idempotency_keys_scope_key"`
+	Scope       string `gorm:"not null;uniqueIndex:idx_idempotency_keys_scope_key"`
+	RequestHash string `gorm:"not null"`
+	Completed   bool   `gorm:"not null;default:false"`
+	StatusCode  int
+	Header      string `gorm:"type:text"`
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
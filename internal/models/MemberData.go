@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// ReturnedMemberAbsentHistory is one absent list entry of a member. ArchivedAt
+// is only set when the form is archived.
+type ReturnedMemberAbsentHistory struct {
+	FormAbsensiID uint       `json:"formAbsensiID"`
+	Title         string     `json:"title"`
+	Keterangan    string     `json:"keterangan"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	ArchivedAt    *time.Time `json:"archivedAt,omitempty"`
+}
+
+// ReturnedMemberPengurus is the pengurus record of a member, by the ids of its
+// departemen and jabatan.
+type ReturnedMemberPengurus struct {
+	DepartemenID int `json:"departemenID"`
+	JabatanID    int `json:"jabatanID"`
+}
+
+type ReturnedMemberUser struct {
+	NPM       string    `json:"npm"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ReturnedMemberData struct {
+	ExportedAt    time.Time                     `json:"exportedAt"`
+	AnggotaBiasa  AnggotaBiasa                  `json:"anggotaBiasa"`
+	Pengurus      *ReturnedMemberPengurus       `json:"pengurus"`
+	User          *ReturnedMemberUser           `json:"user"`
+	AbsentHistory []ReturnedMemberAbsentHistory `json:"absentHistory"`
+}
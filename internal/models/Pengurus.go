@@ -1,10 +1,13 @@
 package models
 
+// Pengurus is removed with its AnggotaBiasa (ON DELETE CASCADE on npm, declared
+// in the migrations, see AbsentList). Departemen and Jabatan can't be deleted
+// while a pengurus still belongs to them.
 type Pengurus struct {
 	NPM          string `gorm:"primaryKey"`
-	DepartemenID int
+	DepartemenID int    `gorm:"index"`
 	JabatanID    int
 	AnggotaBiasa AnggotaBiasa `gorm:"foreignKey:NPM"`
-	Departemen   Departemen   `gorm:"foreignKey:DepartemenID"`
-	Jabatan      Jabatan      `gorm:"foreignKey:JabatanID"`
+	Departemen   Departemen   `gorm:"foreignKey:DepartemenID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Jabatan      Jabatan      `gorm:"foreignKey:JabatanID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
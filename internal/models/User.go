@@ -1,13 +1,16 @@
 package models
 
-import (
-	"github.com/jinzhu/gorm"
-)
+import "time"
 
+// User is an admin login. Like AbsentList it has no DeletedAt, erasing a member
+// must remove its credentials for real. The npm foreign key (ON DELETE CASCADE)
+// is declared in the migrations, see AbsentList.
 type User struct {
-	gorm.Model
-	NPM      string
-	Password string
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	NPM       string `gorm:"not null;uniqueIndex"`
+	Password  string `gorm:"not null"`
 
 	AnggotaBiasa AnggotaBiasa `gorm:"foreignKey:NPM"`
 }
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"himatro-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type gormAbsentListRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormAbsentListRepository) Find(ctx context.Context, formID uint, NPM string) (models.AbsentList, error) {
+	absentList := models.AbsentList{}
+
+	err := r.db.WithContext(ctx).Model(&models.AbsentList{}).
+		Where(&models.AbsentList{
+			FormAbsensiID: formID,
+			NPM:           NPM,
+		}).
+		First(&absentList).Error
+
+	return absentList, notFound(err)
+}
+
+func (r *gormAbsentListRepository) ListByForm(ctx context.Context, formID uint) ([]models.AbsentList, error) {
+	absentLists := []models.AbsentList{}
+
+	err := r.db.WithContext(ctx).Model(&models.AbsentList{}).
+		Where(&models.AbsentList{FormAbsensiID: formID}).
+		Find(&absentLists).Error
+
+	return absentLists, err
+}
+
+func (r *gormAbsentListRepository) ListResult(ctx context.Context, formID uint) ([]models.ReturnedAbsentList, error) {
+	absentLists := []models.ReturnedAbsentList{}
+
+	err := r.db.WithContext(ctx).Model(&models.AbsentList{}).
+		Select("anggota_biasas.nama, absent_lists.npm, absent_lists.updated_at, absent_lists.keterangan, departemens.nama as nama_departemen").
+		Where(&models.AbsentList{FormAbsensiID: formID}).
+		Joins("inner join anggota_biasas on anggota_biasas.npm = absent_lists.npm").
+		Joins("left join pengurus on pengurus.npm = anggota_biasas.npm").
+		Joins("left join departemens on departemens.id = pengurus.departemen_id").
+		Find(&absentLists).Error
+
+	return absentLists, err
+}
+
+func (r *gormAbsentListRepository) ListHistory(ctx context.Context, NPM string) ([]models.ReturnedMemberAbsentHistory, error) {
+	history := []models.ReturnedMemberAbsentHistory{}
+
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.AbsentList{}).
+		Select("absent_lists.form_absensi_id, form_absensis.title, absent_lists.keterangan, absent_lists.created_at, absent_lists.updated_at, form_absensis.deleted_at as archived_at").
+		Joins("inner join form_absensis on form_absensis.id = absent_lists.form_absensi_id").
+		Where("absent_lists.npm = ?", NPM).
+		Order("absent_lists.created_at").
+		Scan(&history).Error
+
+	return history, err
+}
+
+func (r *gormAbsentListRepository) CreateInBatches(ctx context.Context, absentLists []models.AbsentList, batchSize int) error {
+	if len(absentLists) == 0 {
+		return nil
+	}
+
+	return duplicate(r.db.WithContext(ctx).CreateInBatches(&absentLists, batchSize).Error)
+}
+
+// DeleteByForm deletes for real, see models.AbsentList.
+func (r *gormAbsentListRepository) DeleteByForm(ctx context.Context, formID uint) error {
+	return r.db.WithContext(ctx).Unscoped().
+		Where(&models.AbsentList{FormAbsensiID: formID}).
+		Delete(&models.AbsentList{}).Error
+}
+
+func (r *gormAbsentListRepository) UpdateKeterangan(ctx context.Context, formID uint, NPM string, keterangan string) error {
+	res := r.db.WithContext(ctx).Model(&models.AbsentList{}).
+		Where(&models.AbsentList{
+			FormAbsensiID: formID,
+			NPM:           NPM,
+		}).
+		Update("keterangan", keterangan)
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormAbsentListRepository) ReplaceNPM(ctx context.Context, oldNPM string, newNPM string) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.AbsentList{}).
+		Where("npm = ?", oldNPM).
+		UpdateColumn("npm", newNPM).Error
+}
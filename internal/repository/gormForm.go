@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"himatro-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type gormFormRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormFormRepository) Create(ctx context.Context, form *models.FormAbsensi) error {
+	return r.db.WithContext(ctx).Create(form).Error
+}
+
+func (r *gormFormRepository) FindByID(ctx context.Context, id uint) (models.FormAbsensi, error) {
+	form := models.FormAbsensi{}
+
+	err := r.db.WithContext(ctx).Model(&models.FormAbsensi{}).Where("id = ?", id).First(&form).Error
+
+	return form, notFound(err)
+}
+
+func (r *gormFormRepository) UpdateVersioned(ctx context.Context, form *models.FormAbsensi, expectedVersion uint) error {
+	res := r.db.WithContext(ctx).Model(form).
+		Where("version = ?", expectedVersion).
+		Select("*").
+		Omit("created_at").
+		Updates(form)
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	return nil
+}
+
+// Archive bumps the version like Restore, so an ETag taken before the form was
+// archived doesn't match the archived form either. The absent lists keep their
+// updated_at, which is the time they were filled.
+func (r *gormFormRepository) Archive(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.FormAbsensi{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"deleted_at": at,
+				"version":    gorm.Expr("version + 1"),
+			})
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return tx.Model(&models.AbsentList{}).
+			Where("form_absensi_id = ?", id).
+			UpdateColumn("deleted_at", at).Error
+	})
+}
+
+// Restore bumps the version, so an ETag taken before the form was archived
+// can't update the restored form.
+func (r *gormFormRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Model(&models.FormAbsensi{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"version":    gorm.Expr("version + 1"),
+			})
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return tx.Unscoped().Model(&models.AbsentList{}).
+			Where("form_absensi_id = ? AND deleted_at IS NOT NULL", id).
+			UpdateColumn("deleted_at", nil).Error
+	})
+}
+
+// PurgeArchived relies on the ON DELETE CASCADE foreign key of absent_lists to
+// delete the absent lists with their forms.
+func (r *gormFormRepository) PurgeArchived(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Delete(&models.FormAbsensi{})
+
+	return res.RowsAffected, res.Error
+}
+
+// ListDetails only uses SQL that Postgres and SQLite both understand, so the
+// counts are sums of CASE instead of Postgres' count(...) filter (where ...).
+func (r *gormFormRepository) ListDetails(ctx context.Context, limit int, includeArchived bool) ([]models.ReturnedFormAbsentDetails, error) {
+	absentFormsDetails := []models.ReturnedFormAbsentDetails{}
+
+	query := r.db.WithContext(ctx).Model(&models.FormAbsensi{}).
+		Select(`
+			form_absensis.id as form_id,
+			form_absensis.title,
+			form_absensis.created_at,
+			form_absensis.updated_at,
+			form_absensis.participant as participant_code,
+			form_absensis.start_at,
+			form_absensis.finish_at,
+			form_absensis.require_attendance_image_proof,
+			form_absensis.require_execuse_image_proof,
+			form_absensis.result_visibility,
+			form_absensis.time_zone,
+			form_absensis.deleted_at as archived_at,
+			count(absent_lists.id) as total_participant,
+			sum(case when absent_lists.keterangan = 'h' then 1 else 0 end) as hadir,
+			sum(case when absent_lists.keterangan = 'i' then 1 else 0 end) as izin,
+			sum(case when absent_lists.keterangan = '?' then 1 else 0 end) as tanpa_keterangan
+		`).
+		Joins("inner join absent_lists on absent_lists.form_absensi_id = form_absensis.id").
+		Group("form_absensis.id").
+		Order("form_absensis.id")
+
+	if includeArchived {
+		query = query.Unscoped()
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Scan(&absentFormsDetails).Error
+
+	return absentFormsDetails, err
+}
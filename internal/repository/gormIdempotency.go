@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"himatro-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormIdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&models.IdempotencyKey{}).Error
+}
+
+func (r *gormIdempotencyRepository) Reserve(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *gormIdempotencyRepository) Find(ctx context.Context, key string, scope string) (models.IdempotencyKey, error) {
+	stored := models.IdempotencyKey{}
+
+	err := r.db.WithContext(ctx).Where("idempotency_key = ? AND scope = ?", key, scope).First(&stored).Error
+
+	return stored, notFound(err)
+}
+
+func (r *gormIdempotencyRepository) Complete(ctx context.Context, key string, scope string, status int, header string, body []byte) error {
+	return r.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("idempotency_key = ? AND scope = ?", key, scope).
+		Updates(map[string]interface{}{
+			"completed":   true,
+			"status_code": status,
+			"header":      header,
+			"body":        body,
+		}).Error
+}
+
+func (r *gormIdempotencyRepository) Release(ctx context.Context, key string, scope string) error {
+	return r.db.WithContext(ctx).
+		Where("idempotency_key = ? AND scope = ? AND completed = ?", key, scope, false).
+		Delete(&models.IdempotencyKey{}).Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"himatro-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type gormMemberRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormMemberRepository) FindAnggota(ctx context.Context, NPM string) (models.AnggotaBiasa, error) {
+	anggota := models.AnggotaBiasa{}
+
+	err := r.db.WithContext(ctx).Model(&models.AnggotaBiasa{}).Where("npm = ?", NPM).First(&anggota).Error
+
+	return anggota, notFound(err)
+}
+
+func (r *gormMemberRepository) CreateAnggota(ctx context.Context, anggota *models.AnggotaBiasa) error {
+	return r.db.WithContext(ctx).Create(anggota).Error
+}
+
+func (r *gormMemberRepository) DeleteAnggota(ctx context.Context, NPM string) error {
+	return r.db.WithContext(ctx).Where("npm = ?", NPM).Delete(&models.AnggotaBiasa{}).Error
+}
+
+func (r *gormMemberRepository) FindPengurus(ctx context.Context, NPM string) (models.Pengurus, error) {
+	pengurus := models.Pengurus{}
+
+	err := r.db.WithContext(ctx).Model(&models.Pengurus{}).Where("npm = ?", NPM).First(&pengurus).Error
+
+	return pengurus, notFound(err)
+}
+
+func (r *gormMemberRepository) CreatePengurus(ctx context.Context, pengurus *models.Pengurus) error {
+	return r.db.WithContext(ctx).Create(pengurus).Error
+}
+
+func (r *gormMemberRepository) ListPengurus(ctx context.Context, departemenID int) ([]models.Pengurus, error) {
+	pengurus := []models.Pengurus{}
+
+	// if departemenID 0, query all see: https://gorm.io/docs/query.html#Struct-amp-Map-Conditions
+	err := r.db.WithContext(ctx).Where(&models.Pengurus{DepartemenID: departemenID}).Find(&pengurus).Error
+
+	return pengurus, err
+}
+
+func (r *gormMemberRepository) DeletePengurus(ctx context.Context, NPM string) error {
+	return r.db.WithContext(ctx).Where("npm = ?", NPM).Delete(&models.Pengurus{}).Error
+}
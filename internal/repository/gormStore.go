@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the Postgres error code of a unique index violation.
+const uniqueViolation = "23505"
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a Store backed by the given GORM connection.
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Forms() FormRepository {
+	return &gormFormRepository{db: s.db}
+}
+
+func (s *gormStore) AbsentLists() AbsentListRepository {
+	return &gormAbsentListRepository{db: s.db}
+}
+
+func (s *gormStore) Members() MemberRepository {
+	return &gormMemberRepository{db: s.db}
+}
+
+func (s *gormStore) Users() UserRepository {
+	return &gormUserRepository{db: s.db}
+}
+
+func (s *gormStore) IdempotencyKeys() IdempotencyRepository {
+	return &gormIdempotencyRepository{db: s.db}
+}
+
+func (s *gormStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+func (s *gormStore) Ping(ctx context.Context) error {
+	if s.db == nil {
+		return errors.New("database is not connected")
+	}
+
+	sqlDB, err := s.db.DB()
+
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+func (s *gormStore) SchemaVersion(ctx context.Context) (uint, error) {
+	if s.db == nil {
+		return 0, errors.New("database is not connected")
+	}
+
+	var version uint
+
+	err := s.db.WithContext(ctx).Table("schema_migrations").
+		Select("coalesce(max(version), 0)").
+		Scan(&version).Error
+
+	return version, err
+}
+
+// notFound translates the GORM not found error to ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// duplicate translates a unique index violation to ErrDuplicate. SQLite errors
+// are matched by message, their type only exists in cgo builds.
+func duplicate(err error) error {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return fmt.Errorf("%w: %s", ErrDuplicate, pgErr.ConstraintName)
+	}
+
+	if err != nil && strings.HasPrefix(err.Error(), "UNIQUE constraint failed") {
+		return fmt.Errorf("%w: %s", ErrDuplicate, strings.TrimPrefix(err.Error(), "UNIQUE constraint failed: "))
+	}
+
+	return err
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"himatro-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) FindByNPM(ctx context.Context, NPM string) (models.User, error) {
+	user := models.User{}
+
+	err := r.db.WithContext(ctx).Where("npm = ?", NPM).First(&user).Error
+
+	return user, notFound(err)
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return duplicate(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *gormUserRepository) DeleteByNPM(ctx context.Context, NPM string) error {
+	return r.db.WithContext(ctx).Where("npm = ?", NPM).Delete(&models.User{}).Error
+}
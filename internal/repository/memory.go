@@ -0,0 +1,777 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"himatro-api/internal/clock"
+	"himatro-api/internal/models"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryStore is a Store that keeps every record in memory. It is meant for
+// tests and local experiments: transactions are serialized, and a rolled back
+// transaction also discards writes made outside of it while it was running.
+type MemoryStore struct {
+	state *memoryState
+	inTx  bool
+}
+
+type memoryState struct {
+	mu    sync.Mutex
+	txMu  sync.Mutex
+	clock clock.Clock
+	data  memoryData
+
+	schemaVersion uint
+}
+
+type memoryData struct {
+	forms       map[uint]models.FormAbsensi
+	absentLists []models.AbsentList
+	anggota     map[string]models.AnggotaBiasa
+	pengurus    map[string]models.Pengurus
+	departemen  map[int]models.Departemen
+	users       map[string]models.User
+	keys        map[[2]string]models.IdempotencyKey
+
+	nextFormID       uint
+	nextAbsentListID uint
+	nextUserID       uint
+	nextKeyID        uint
+}
+
+// NewMemoryStore returns an empty MemoryStore. Timestamps of the records are taken from clk.
+func NewMemoryStore(clk clock.Clock) *MemoryStore {
+	return &MemoryStore{
+		state: &memoryState{
+			clock: clk,
+			data: memoryData{
+				forms:      map[uint]models.FormAbsensi{},
+				anggota:    map[string]models.AnggotaBiasa{},
+				pengurus:   map[string]models.Pengurus{},
+				departemen: map[int]models.Departemen{},
+				users:      map[string]models.User{},
+				keys:       map[[2]string]models.IdempotencyKey{},
+			},
+		},
+	}
+}
+
+// AddDepartemen stores a departemen, it's needed to list the result of a form.
+func (s *MemoryStore) AddDepartemen(departemen models.Departemen) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	s.state.data.departemen[departemen.ID] = departemen
+}
+
+func (s *MemoryStore) Forms() FormRepository {
+	return &memoryFormRepository{state: s.state}
+}
+
+func (s *MemoryStore) AbsentLists() AbsentListRepository {
+	return &memoryAbsentListRepository{state: s.state}
+}
+
+func (s *MemoryStore) Members() MemberRepository {
+	return &memoryMemberRepository{state: s.state}
+}
+
+func (s *MemoryStore) Users() UserRepository {
+	return &memoryUserRepository{state: s.state}
+}
+
+func (s *MemoryStore) IdempotencyKeys() IdempotencyRepository {
+	return &memoryIdempotencyRepository{state: s.state}
+}
+
+func (s *MemoryStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.state.txMu.Lock()
+	defer s.state.txMu.Unlock()
+
+	s.state.mu.Lock()
+	snapshot := s.state.data.clone()
+	s.state.mu.Unlock()
+
+	if err := fn(&MemoryStore{state: s.state, inTx: true}); err != nil {
+		s.state.mu.Lock()
+		s.state.data = snapshot
+		s.state.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// SetSchemaVersion sets the version SchemaVersion reports, the memory store has
+// no migrations of its own.
+func (s *MemoryStore) SetSchemaVersion(version uint) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	s.state.schemaVersion = version
+}
+
+func (s *MemoryStore) SchemaVersion(ctx context.Context) (uint, error) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	return s.state.schemaVersion, nil
+}
+
+func (d memoryData) clone() memoryData {
+	cloned := d
+
+	cloned.forms = make(map[uint]models.FormAbsensi, len(d.forms))
+	for id, form := range d.forms {
+		cloned.forms[id] = form
+	}
+
+	cloned.absentLists = append([]models.AbsentList(nil), d.absentLists...)
+
+	cloned.anggota = make(map[string]models.AnggotaBiasa, len(d.anggota))
+	for NPM, anggota := range d.anggota {
+		cloned.anggota[NPM] = anggota
+	}
+
+	cloned.pengurus = make(map[string]models.Pengurus, len(d.pengurus))
+	for NPM, pengurus := range d.pengurus {
+		cloned.pengurus[NPM] = pengurus
+	}
+
+	cloned.departemen = make(map[int]models.Departemen, len(d.departemen))
+	for id, departemen := range d.departemen {
+		cloned.departemen[id] = departemen
+	}
+
+	cloned.users = make(map[string]models.User, len(d.users))
+	for NPM, user := range d.users {
+		cloned.users[NPM] = user
+	}
+
+	cloned.keys = make(map[[2]string]models.IdempotencyKey, len(d.keys))
+	for k, key := range d.keys {
+		key.Body = append([]byte(nil), key.Body...)
+		cloned.keys[k] = key
+	}
+
+	return cloned
+}
+
+type memoryFormRepository struct {
+	state *memoryState
+}
+
+func (r *memoryFormRepository) Create(ctx context.Context, form *models.FormAbsensi) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	now := r.state.clock.Now()
+
+	r.state.data.nextFormID++
+	form.ID = r.state.data.nextFormID
+	form.CreatedAt = now
+	form.UpdatedAt = now
+
+	if form.Version == 0 {
+		form.Version = 1
+	}
+
+	if form.ResultVisibility == "" {
+		form.ResultVisibility = models.ResultVisibilityPublic
+	}
+
+	r.state.data.forms[form.ID] = *form
+
+	return nil
+}
+
+func (r *memoryFormRepository) FindByID(ctx context.Context, id uint) (models.FormAbsensi, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	form, ok := r.state.data.forms[id]
+
+	if !ok || form.DeletedAt.Valid {
+		return models.FormAbsensi{}, ErrNotFound
+	}
+
+	return form, nil
+}
+
+func (r *memoryFormRepository) UpdateVersioned(ctx context.Context, form *models.FormAbsensi, expectedVersion uint) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	stored, ok := r.state.data.forms[form.ID]
+
+	if !ok || stored.DeletedAt.Valid || stored.Version != expectedVersion {
+		return ErrVersionConflict
+	}
+
+	form.CreatedAt = stored.CreatedAt
+	form.UpdatedAt = r.state.clock.Now()
+	r.state.data.forms[form.ID] = *form
+
+	return nil
+}
+
+func (r *memoryFormRepository) Archive(ctx context.Context, id uint, at time.Time) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	form, ok := r.state.data.forms[id]
+
+	if !ok || form.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	form.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+	form.Version++
+	form.UpdatedAt = r.state.clock.Now()
+	r.state.data.forms[id] = form
+
+	for i, absentList := range r.state.data.absentLists {
+		if absentList.FormAbsensiID == id && !absentList.DeletedAt.Valid {
+			r.state.data.absentLists[i].DeletedAt = form.DeletedAt
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryFormRepository) Restore(ctx context.Context, id uint) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	form, ok := r.state.data.forms[id]
+
+	if !ok || !form.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	form.DeletedAt = gorm.DeletedAt{}
+	form.Version++
+	form.UpdatedAt = r.state.clock.Now()
+	r.state.data.forms[id] = form
+
+	for i, absentList := range r.state.data.absentLists {
+		if absentList.FormAbsensiID == id {
+			r.state.data.absentLists[i].DeletedAt = gorm.DeletedAt{}
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryFormRepository) PurgeArchived(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	purged := map[uint]bool{}
+
+	for id, form := range r.state.data.forms {
+		if form.DeletedAt.Valid && form.DeletedAt.Time.Before(cutoff) {
+			purged[id] = true
+			delete(r.state.data.forms, id)
+		}
+	}
+
+	kept := r.state.data.absentLists[:0]
+
+	for _, absentList := range r.state.data.absentLists {
+		if !purged[absentList.FormAbsensiID] {
+			kept = append(kept, absentList)
+		}
+	}
+
+	r.state.data.absentLists = kept
+
+	return int64(len(purged)), nil
+}
+
+func (r *memoryFormRepository) ListDetails(ctx context.Context, limit int, includeArchived bool) ([]models.ReturnedFormAbsentDetails, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	details := map[uint]*models.ReturnedFormAbsentDetails{}
+
+	for _, absentList := range r.state.data.absentLists {
+		form, ok := r.state.data.forms[absentList.FormAbsensiID]
+
+		if !ok || (form.DeletedAt.Valid && !includeArchived) {
+			continue
+		}
+
+		detail, ok := details[form.ID]
+
+		if !ok {
+			detail = &models.ReturnedFormAbsentDetails{
+				FormID:                      form.ID,
+				Title:                       form.Title,
+				CreatedAt:                   form.CreatedAt,
+				UpdatedAt:                   form.UpdatedAt,
+				ParticipantCode:             form.Participant,
+				StartAt:                     form.StartAt,
+				FinishAt:                    form.FinishAt,
+				RequireAttendanceImageProof: form.RequireAttendanceImageProof,
+				RequireExecuseImageProof:    form.RequireExecuseImageProof,
+				ResultVisibility:            form.ResultVisibility,
+				TimeZone:                    form.TimeZone,
+			}
+
+			if form.DeletedAt.Valid {
+				archivedAt := form.DeletedAt.Time
+				detail.ArchivedAt = &archivedAt
+			}
+
+			details[form.ID] = detail
+		}
+
+		detail.TotalParticipant++
+
+		switch absentList.Keterangan {
+		case "h":
+			detail.Hadir++
+		case "i":
+			detail.Izin++
+		case "?":
+			detail.TanpaKeterangan++
+		}
+	}
+
+	absentFormsDetails := make([]models.ReturnedFormAbsentDetails, 0, len(details))
+
+	for _, detail := range details {
+		absentFormsDetails = append(absentFormsDetails, *detail)
+	}
+
+	sort.Slice(absentFormsDetails, func(i, j int) bool {
+		return absentFormsDetails[i].FormID < absentFormsDetails[j].FormID
+	})
+
+	if limit > 0 && len(absentFormsDetails) > limit {
+		absentFormsDetails = absentFormsDetails[:limit]
+	}
+
+	return absentFormsDetails, nil
+}
+
+type memoryAbsentListRepository struct {
+	state *memoryState
+}
+
+func (r *memoryAbsentListRepository) Find(ctx context.Context, formID uint, NPM string) (models.AbsentList, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	for _, absentList := range r.state.data.absentLists {
+		if absentList.FormAbsensiID == formID && absentList.NPM == NPM && !absentList.DeletedAt.Valid {
+			return absentList, nil
+		}
+	}
+
+	return models.AbsentList{}, ErrNotFound
+}
+
+func (r *memoryAbsentListRepository) ListByForm(ctx context.Context, formID uint) ([]models.AbsentList, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	absentLists := []models.AbsentList{}
+
+	for _, absentList := range r.state.data.absentLists {
+		if absentList.FormAbsensiID == formID && !absentList.DeletedAt.Valid {
+			absentLists = append(absentLists, absentList)
+		}
+	}
+
+	return absentLists, nil
+}
+
+func (r *memoryAbsentListRepository) ListResult(ctx context.Context, formID uint) ([]models.ReturnedAbsentList, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	absentLists := []models.ReturnedAbsentList{}
+
+	for _, absentList := range r.state.data.absentLists {
+		if absentList.FormAbsensiID != formID || absentList.DeletedAt.Valid {
+			continue
+		}
+
+		anggota, ok := r.state.data.anggota[absentList.NPM]
+
+		if !ok {
+			continue
+		}
+
+		// like the left joins of the gorm store, a member who is no pengurus has no departemen
+		pengurus := r.state.data.pengurus[absentList.NPM]
+		departemen := r.state.data.departemen[pengurus.DepartemenID]
+
+		absentLists = append(absentLists, models.ReturnedAbsentList{
+			NPM:            absentList.NPM,
+			UpdatedAt:      absentList.UpdatedAt,
+			Keterangan:     absentList.Keterangan,
+			Nama:           anggota.Nama,
+			NamaDepartemen: departemen.Nama,
+		})
+	}
+
+	return absentLists, nil
+}
+
+func (r *memoryAbsentListRepository) ListHistory(ctx context.Context, NPM string) ([]models.ReturnedMemberAbsentHistory, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	history := []models.ReturnedMemberAbsentHistory{}
+
+	for _, absentList := range r.state.data.absentLists {
+		if absentList.NPM != NPM {
+			continue
+		}
+
+		form, ok := r.state.data.forms[absentList.FormAbsensiID]
+
+		if !ok {
+			continue
+		}
+
+		entry := models.ReturnedMemberAbsentHistory{
+			FormAbsensiID: absentList.FormAbsensiID,
+			Title:         form.Title,
+			Keterangan:    absentList.Keterangan,
+			CreatedAt:     absentList.CreatedAt,
+			UpdatedAt:     absentList.UpdatedAt,
+		}
+
+		if form.DeletedAt.Valid {
+			archivedAt := form.DeletedAt.Time
+			entry.ArchivedAt = &archivedAt
+		}
+
+		history = append(history, entry)
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].CreatedAt.Before(history[j].CreatedAt)
+	})
+
+	return history, nil
+}
+
+// absentListKey mirrors the unique (form_absensi_id, npm) index.
+type absentListKey struct {
+	formID uint
+	NPM    string
+}
+
+func (r *memoryAbsentListRepository) CreateInBatches(ctx context.Context, absentLists []models.AbsentList, batchSize int) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	seen := map[absentListKey]bool{}
+
+	for _, absentList := range r.state.data.absentLists {
+		seen[absentListKey{absentList.FormAbsensiID, absentList.NPM}] = true
+	}
+
+	for _, absentList := range absentLists {
+		key := absentListKey{absentList.FormAbsensiID, absentList.NPM}
+
+		if seen[key] {
+			return fmt.Errorf("%w: form %d already has npm %s", ErrDuplicate, key.formID, key.NPM)
+		}
+
+		seen[key] = true
+	}
+
+	now := r.state.clock.Now()
+
+	for i := range absentLists {
+		r.state.data.nextAbsentListID++
+		absentLists[i].ID = r.state.data.nextAbsentListID
+		absentLists[i].CreatedAt = now
+		absentLists[i].UpdatedAt = now
+
+		if absentLists[i].Keterangan == "" {
+			absentLists[i].Keterangan = "?"
+		}
+
+		r.state.data.absentLists = append(r.state.data.absentLists, absentLists[i])
+	}
+
+	return nil
+}
+
+func (r *memoryAbsentListRepository) DeleteByForm(ctx context.Context, formID uint) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	kept := r.state.data.absentLists[:0]
+
+	for _, absentList := range r.state.data.absentLists {
+		if absentList.FormAbsensiID != formID {
+			kept = append(kept, absentList)
+		}
+	}
+
+	r.state.data.absentLists = kept
+
+	return nil
+}
+
+func (r *memoryAbsentListRepository) UpdateKeterangan(ctx context.Context, formID uint, NPM string, keterangan string) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	for i, absentList := range r.state.data.absentLists {
+		if absentList.FormAbsensiID == formID && absentList.NPM == NPM && !absentList.DeletedAt.Valid {
+			r.state.data.absentLists[i].Keterangan = keterangan
+			r.state.data.absentLists[i].UpdatedAt = r.state.clock.Now()
+
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (r *memoryAbsentListRepository) ReplaceNPM(ctx context.Context, oldNPM string, newNPM string) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	for i, absentList := range r.state.data.absentLists {
+		if absentList.NPM == oldNPM {
+			r.state.data.absentLists[i].NPM = newNPM
+		}
+	}
+
+	return nil
+}
+
+type memoryMemberRepository struct {
+	state *memoryState
+}
+
+func (r *memoryMemberRepository) FindAnggota(ctx context.Context, NPM string) (models.AnggotaBiasa, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	anggota, ok := r.state.data.anggota[NPM]
+
+	if !ok {
+		return models.AnggotaBiasa{}, ErrNotFound
+	}
+
+	return anggota, nil
+}
+
+func (r *memoryMemberRepository) CreateAnggota(ctx context.Context, anggota *models.AnggotaBiasa) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	r.state.data.anggota[anggota.NPM] = *anggota
+
+	return nil
+}
+
+func (r *memoryMemberRepository) DeleteAnggota(ctx context.Context, NPM string) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	delete(r.state.data.anggota, NPM)
+
+	return nil
+}
+
+func (r *memoryMemberRepository) FindPengurus(ctx context.Context, NPM string) (models.Pengurus, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	pengurus, ok := r.state.data.pengurus[NPM]
+
+	if !ok {
+		return models.Pengurus{}, ErrNotFound
+	}
+
+	return pengurus, nil
+}
+
+func (r *memoryMemberRepository) CreatePengurus(ctx context.Context, pengurus *models.Pengurus) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	r.state.data.pengurus[pengurus.NPM] = *pengurus
+
+	return nil
+}
+
+func (r *memoryMemberRepository) ListPengurus(ctx context.Context, departemenID int) ([]models.Pengurus, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	pengurus := []models.Pengurus{}
+
+	for _, p := range r.state.data.pengurus {
+		if departemenID == 0 || p.DepartemenID == departemenID {
+			pengurus = append(pengurus, p)
+		}
+	}
+
+	sort.Slice(pengurus, func(i, j int) bool {
+		return pengurus[i].NPM < pengurus[j].NPM
+	})
+
+	return pengurus, nil
+}
+
+func (r *memoryMemberRepository) DeletePengurus(ctx context.Context, NPM string) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	delete(r.state.data.pengurus, NPM)
+
+	return nil
+}
+
+type memoryUserRepository struct {
+	state *memoryState
+}
+
+func (r *memoryUserRepository) FindByNPM(ctx context.Context, NPM string) (models.User, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	user, ok := r.state.data.users[NPM]
+
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+
+	return user, nil
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	if _, ok := r.state.data.users[user.NPM]; ok {
+		return fmt.Errorf("%w: user %s", ErrDuplicate, user.NPM)
+	}
+
+	now := r.state.clock.Now()
+
+	r.state.data.nextUserID++
+	user.ID = r.state.data.nextUserID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.state.data.users[user.NPM] = *user
+
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteByNPM(ctx context.Context, NPM string) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	delete(r.state.data.users, NPM)
+
+	return nil
+}
+
+type memoryIdempotencyRepository struct {
+	state *memoryState
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	for k, key := range r.state.data.keys {
+		if key.ExpiresAt.Before(now) {
+			delete(r.state.data.keys, k)
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Reserve(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	k := [2]string{key.Key, key.Scope}
+
+	if _, ok := r.state.data.keys[k]; ok {
+		return false, nil
+	}
+
+	r.state.data.nextKeyID++
+	key.ID = r.state.data.nextKeyID
+	key.CreatedAt = r.state.clock.Now()
+	r.state.data.keys[k] = *key
+
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepository) Find(ctx context.Context, key string, scope string) (models.IdempotencyKey, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	stored, ok := r.state.data.keys[[2]string{key, scope}]
+
+	if !ok {
+		return models.IdempotencyKey{}, ErrNotFound
+	}
+
+	return stored, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(ctx context.Context, key string, scope string, status int, header string, body []byte) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	k := [2]string{key, scope}
+	stored, ok := r.state.data.keys[k]
+
+	if !ok {
+		return nil
+	}
+
+	stored.Completed = true
+	stored.StatusCode = status
+	stored.Header = header
+	stored.Body = append([]byte(nil), body...)
+	r.state.data.keys[k] = stored
+
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Release(ctx context.Context, key string, scope string) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	k := [2]string{key, scope}
+
+	if stored, ok := r.state.data.keys[k]; ok && !stored.Completed {
+		delete(r.state.data.keys, k)
+	}
+
+	return nil
+}
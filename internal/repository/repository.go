@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"himatro-api/internal/models"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+
+	// ErrVersionConflict is returned when a versioned update finds that the record
+	// was changed since it was read.
+	ErrVersionConflict = errors.New("record was modified since it was read")
+
+	// ErrDuplicate is returned when a write would break a unique index, e.g. a
+	// second absent list row for the same form and NPM.
+	ErrDuplicate = errors.New("record already exists")
+)
+
+type FormRepository interface {
+	Create(ctx context.Context, form *models.FormAbsensi) error
+	FindByID(ctx context.Context, id uint) (models.FormAbsensi, error)
+
+	// UpdateVersioned writes every field of form, but only if the stored version
+	// is still expectedVersion. form.Version must already hold the new version.
+	UpdateVersioned(ctx context.Context, form *models.FormAbsensi, expectedVersion uint) error
+
+	// Archive soft deletes the form and its absent lists at the given time, so
+	// FindByID no longer finds it, and bumps its version. ErrNotFound means there
+	// is no unarchived form with that id.
+	Archive(ctx context.Context, id uint, at time.Time) error
+
+	// Restore unarchives the form with its absent lists and bumps its version.
+	// ErrNotFound means there is no archived form with that id.
+	Restore(ctx context.Context, id uint) error
+
+	// PurgeArchived permanently deletes the forms archived before cutoff together
+	// with their absent lists, and returns how many forms were deleted.
+	PurgeArchived(ctx context.Context, cutoff time.Time) (int64, error)
+
+	// ListDetails returns every form with its attendance counts. 0 means no limit.
+	// Archived forms are only listed when includeArchived is set.
+	ListDetails(ctx context.Context, limit int, includeArchived bool) ([]models.ReturnedFormAbsentDetails, error)
+}
+
+// AbsentListRepository leaves out the absent lists of archived forms, except
+// in ListHistory, DeleteByForm and ReplaceNPM.
+type AbsentListRepository interface {
+	Find(ctx context.Context, formID uint, NPM string) (models.AbsentList, error)
+	ListByForm(ctx context.Context, formID uint) ([]models.AbsentList, error)
+
+	// ListResult returns the absent list of a form joined with member names and
+	// departemen. Members who are no pengurus, like erased ones, have no departemen.
+	ListResult(ctx context.Context, formID uint) ([]models.ReturnedAbsentList, error)
+
+	// ListHistory returns every absent list entry of a member, oldest first,
+	// with the entries of archived forms marked by their archive time.
+	ListHistory(ctx context.Context, NPM string) ([]models.ReturnedMemberAbsentHistory, error)
+
+	CreateInBatches(ctx context.Context, absentLists []models.AbsentList, batchSize int) error
+	DeleteByForm(ctx context.Context, formID uint) error
+	UpdateKeterangan(ctx context.Context, formID uint, NPM string, keterangan string) error
+	ReplaceNPM(ctx context.Context, oldNPM string, newNPM string) error
+}
+
+type MemberRepository interface {
+	FindAnggota(ctx context.Context, NPM string) (models.AnggotaBiasa, error)
+	CreateAnggota(ctx context.Context, anggota *models.AnggotaBiasa) error
+	DeleteAnggota(ctx context.Context, NPM string) error
+
+	FindPengurus(ctx context.Context, NPM string) (models.Pengurus, error)
+	CreatePengurus(ctx context.Context, pengurus *models.Pengurus) error
+
+	// ListPengurus returns the pengurus of a departemen, or every pengurus when departemenID is 0.
+	ListPengurus(ctx context.Context, departemenID int) ([]models.Pengurus, error)
+	DeletePengurus(ctx context.Context, NPM string) error
+}
+
+type UserRepository interface {
+	FindByNPM(ctx context.Context, NPM string) (models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	DeleteByNPM(ctx context.Context, NPM string) error
+}
+
+type IdempotencyRepository interface {
+	DeleteExpired(ctx context.Context, now time.Time) error
+
+	// Reserve stores the key, and reports false if it is already stored.
+	Reserve(ctx context.Context, key *models.IdempotencyKey) (bool, error)
+	Find(ctx context.Context, key string, scope string) (models.IdempotencyKey, error)
+	Complete(ctx context.Context, key string, scope string, status int, header string, body []byte) error
+
+	// Release deletes the key unless its response was already stored.
+	Release(ctx context.Context, key string, scope string) error
+}
+
+// Store gives access to every repository. The repositories of the Store passed to
+// a Transaction callback share that transaction.
+type Store interface {
+	Forms() FormRepository
+	AbsentLists() AbsentListRepository
+	Members() MemberRepository
+	Users() UserRepository
+	IdempotencyKeys() IdempotencyRepository
+
+	// Transaction commits every write made through tx if fn returns nil, and
+	// rolls all of them back otherwise.
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+
+	Ping(ctx context.Context) error
+
+	// SchemaVersion is the newest migration recorded in schema_migrations, 0
+	// when none is. It never creates the table.
+	SchemaVersion(ctx context.Context) (uint, error)
+}
@@ -0,0 +1,740 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"himatro-api/internal/clock"
+	"himatro-api/internal/config"
+	"himatro-api/internal/migration"
+	"himatro-api/internal/models"
+	"himatro-api/internal/repository"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// testStore is a Store under test with a way to add the records it has no
+// repository for.
+type testStore struct {
+	repository.Store
+
+	clock         *clock.Fake
+	addDepartemen func(t *testing.T, departemen models.Departemen)
+}
+
+// storeImplementations are the Stores the contract runs against. Pengurus of a
+// test store may use jabatan 1.
+var storeImplementations = []struct {
+	name string
+	open func(t *testing.T) testStore
+}{
+	{"memory", openMemoryStore},
+	{"sqlite", openSQLiteStore},
+}
+
+func openMemoryStore(t *testing.T) testStore {
+	clk := clock.NewFake(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC))
+	store := repository.NewMemoryStore(clk)
+
+	return testStore{
+		Store: store,
+		clock: clk,
+		addDepartemen: func(t *testing.T, departemen models.Departemen) {
+			store.AddDepartemen(departemen)
+		},
+	}
+}
+
+// openSQLiteStore migrates a new SQLite database the way `main migrate up` does.
+// The gorm store stamps records with the system time, so only the times passed
+// to it come from the fake clock.
+func openSQLiteStore(t *testing.T) testStore {
+	clk := clock.NewFake(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC))
+
+	dbConf := config.Database{SQLitePath: filepath.Join(t.TempDir(), "himatro.db")}
+
+	db, err := gorm.Open(sqlite.Open(dbConf.SQLiteDSN()), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		t.Fatalf("get sqlite connection pool: %v", err)
+	}
+
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrations, err := migration.Embedded(config.DriverSQLite)
+
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+
+	if _, err := migration.New(db, migrations, clk).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if err := db.Create(&models.Jabatan{ID: 1, PrivilegeLevel: 1, Name: "Anggota"}).Error; err != nil {
+		t.Fatalf("create jabatan: %v", err)
+	}
+
+	return testStore{
+		Store: repository.NewGormStore(db),
+		clock: clk,
+		addDepartemen: func(t *testing.T, departemen models.Departemen) {
+			if err := db.Create(&departemen).Error; err != nil {
+				t.Fatalf("create departemen: %v", err)
+			}
+		},
+	}
+}
+
+// storeContract is the behavior every Store must share, so a test written
+// against the memory store holds on the database too.
+var storeContract = []struct {
+	name string
+	run  func(t *testing.T, ctx context.Context, s testStore)
+}{
+	{"form is created at version 1", testFormCreate},
+	{"unknown form is not found", testFormNotFound},
+	{"versioned update detects conflicts", testFormUpdateVersioned},
+	{"archive hides the form until restored", testFormArchiveRestore},
+	{"purge deletes forms archived before the cutoff", testFormPurgeArchived},
+	{"form details count attendance", testFormListDetails},
+	{"absent list is unique per form and npm", testAbsentListDuplicate},
+	{"absent list is filled", testAbsentListUpdateKeterangan},
+	{"absent list result has names and departemen", testAbsentListResult},
+	{"absent list of an archived form is hidden but exported", testAbsentListArchived},
+	{"absent list is deleted by form", testAbsentListDeleteByForm},
+	{"erased member keeps the absent lists as a pseudonym", testEraseMember},
+	{"user is unique per npm", testUser},
+	{"failed transaction is rolled back", testTransactionRollback},
+	{"idempotency key is reserved once", testIdempotencyKey},
+}
+
+func TestStoreContract(t *testing.T) {
+	for _, impl := range storeImplementations {
+		impl := impl
+
+		t.Run(impl.name, func(t *testing.T) {
+			for _, tc := range storeContract {
+				tc := tc
+
+				t.Run(tc.name, func(t *testing.T) {
+					tc.run(t, context.Background(), impl.open(t))
+				})
+			}
+		})
+	}
+}
+
+func createForm(t *testing.T, ctx context.Context, s testStore, title string) models.FormAbsensi {
+	t.Helper()
+
+	now := s.clock.Now()
+
+	form := models.FormAbsensi{
+		Title:    title,
+		StartAt:  now,
+		FinishAt: now.Add(time.Hour),
+	}
+
+	if err := s.Forms().Create(ctx, &form); err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+
+	return form
+}
+
+// createMember adds a pengurus of the departemen, departemenID 0 only adds the anggota.
+func createMember(t *testing.T, ctx context.Context, s testStore, NPM string, nama string, departemenID int) {
+	t.Helper()
+
+	if err := s.Members().CreateAnggota(ctx, &models.AnggotaBiasa{NPM: NPM, Nama: nama}); err != nil {
+		t.Fatalf("create anggota %s: %v", NPM, err)
+	}
+
+	if departemenID == 0 {
+		return
+	}
+
+	if err := s.Members().CreatePengurus(ctx, &models.Pengurus{NPM: NPM, DepartemenID: departemenID, JabatanID: 1}); err != nil {
+		t.Fatalf("create pengurus %s: %v", NPM, err)
+	}
+}
+
+func createAbsentList(t *testing.T, ctx context.Context, s testStore, formID uint, NPMs ...string) {
+	t.Helper()
+
+	absentLists := []models.AbsentList{}
+
+	for _, NPM := range NPMs {
+		absentLists = append(absentLists, models.AbsentList{FormAbsensiID: formID, NPM: NPM})
+	}
+
+	if err := s.AbsentLists().CreateInBatches(ctx, absentLists, 100); err != nil {
+		t.Fatalf("create absent list: %v", err)
+	}
+}
+
+func testFormCreate(t *testing.T, ctx context.Context, s testStore) {
+	form := createForm(t, ctx, s, "Rapat")
+
+	if form.ID == 0 {
+		t.Fatal("created form has no id")
+	}
+
+	found, err := s.Forms().FindByID(ctx, form.ID)
+
+	if err != nil {
+		t.Fatalf("find form: %v", err)
+	}
+
+	if found.Title != "Rapat" || found.Version != 1 || found.ResultVisibility != models.ResultVisibilityPublic {
+		t.Errorf("found title %q, version %d, visibility %q, want Rapat, 1, public", found.Title, found.Version, found.ResultVisibility)
+	}
+
+	if !found.FinishAt.Equal(form.FinishAt) {
+		t.Errorf("found finishAt %v, want %v", found.FinishAt, form.FinishAt)
+	}
+}
+
+func testFormNotFound(t *testing.T, ctx context.Context, s testStore) {
+	if _, err := s.Forms().FindByID(ctx, 404); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find unknown form: got %v, want ErrNotFound", err)
+	}
+}
+
+func testFormUpdateVersioned(t *testing.T, ctx context.Context, s testStore) {
+	form := createForm(t, ctx, s, "Rapat")
+
+	form.Title = "Rapat Pleno"
+	form.Version = 2
+
+	if err := s.Forms().UpdateVersioned(ctx, &form, 1); err != nil {
+		t.Fatalf("update at the current version: %v", err)
+	}
+
+	form.Title = "Rapat Lama"
+	form.Version = 2
+
+	if err := s.Forms().UpdateVersioned(ctx, &form, 1); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("update at a stale version: got %v, want ErrVersionConflict", err)
+	}
+
+	found, err := s.Forms().FindByID(ctx, form.ID)
+
+	if err != nil {
+		t.Fatalf("find form: %v", err)
+	}
+
+	if found.Title != "Rapat Pleno" || found.Version != 2 {
+		t.Errorf("found title %q at version %d, want Rapat Pleno at 2", found.Title, found.Version)
+	}
+}
+
+func testFormArchiveRestore(t *testing.T, ctx context.Context, s testStore) {
+	form := createForm(t, ctx, s, "Rapat")
+
+	if err := s.Forms().Archive(ctx, form.ID, s.clock.Now()); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	if _, err := s.Forms().FindByID(ctx, form.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find archived form: got %v, want ErrNotFound", err)
+	}
+
+	if err := s.Forms().Archive(ctx, form.ID, s.clock.Now()); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("archive twice: got %v, want ErrNotFound", err)
+	}
+
+	if err := s.Forms().Restore(ctx, form.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	if err := s.Forms().Restore(ctx, form.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("restore twice: got %v, want ErrNotFound", err)
+	}
+
+	restored, err := s.Forms().FindByID(ctx, form.ID)
+
+	if err != nil {
+		t.Fatalf("find restored form: %v", err)
+	}
+
+	// archiving and restoring both bump the version
+	if restored.Version != form.Version+2 {
+		t.Errorf("restored version %d, want %d", restored.Version, form.Version+2)
+	}
+}
+
+func testFormPurgeArchived(t *testing.T, ctx context.Context, s testStore) {
+	createMember(t, ctx, s, "2115061001", "Andi", 0)
+
+	old := createForm(t, ctx, s, "Lama")
+	recent := createForm(t, ctx, s, "Baru")
+	kept := createForm(t, ctx, s, "Aktif")
+
+	createAbsentList(t, ctx, s, old.ID, "2115061001")
+
+	now := s.clock.Now()
+
+	if err := s.Forms().Archive(ctx, old.ID, now.Add(-48*time.Hour)); err != nil {
+		t.Fatalf("archive old form: %v", err)
+	}
+
+	if err := s.Forms().Archive(ctx, recent.ID, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("archive recent form: %v", err)
+	}
+
+	purged, err := s.Forms().PurgeArchived(ctx, now.Add(-24*time.Hour))
+
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	if purged != 1 {
+		t.Errorf("purged %d forms, want 1", purged)
+	}
+
+	if err := s.Forms().Restore(ctx, old.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("restore purged form: got %v, want ErrNotFound", err)
+	}
+
+	if err := s.Forms().Restore(ctx, recent.ID); err != nil {
+		t.Errorf("restore recently archived form: %v", err)
+	}
+
+	if _, err := s.Forms().FindByID(ctx, kept.ID); err != nil {
+		t.Errorf("find unarchived form: %v", err)
+	}
+
+	history, err := s.AbsentLists().ListHistory(ctx, "2115061001")
+
+	if err != nil {
+		t.Fatalf("list history: %v", err)
+	}
+
+	if len(history) != 0 {
+		t.Errorf("history has %d entries of the purged form, want none", len(history))
+	}
+}
+
+func testFormListDetails(t *testing.T, ctx context.Context, s testStore) {
+	createMember(t, ctx, s, "2115061001", "Andi", 0)
+	createMember(t, ctx, s, "2115061002", "Budi", 0)
+	createMember(t, ctx, s, "2115061003", "Citra", 0)
+
+	form := createForm(t, ctx, s, "Rapat")
+	archived := createForm(t, ctx, s, "Lama")
+
+	createAbsentList(t, ctx, s, form.ID, "2115061001", "2115061002", "2115061003")
+	createAbsentList(t, ctx, s, archived.ID, "2115061001")
+
+	if err := s.AbsentLists().UpdateKeterangan(ctx, form.ID, "2115061001", "h"); err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+
+	if err := s.AbsentLists().UpdateKeterangan(ctx, form.ID, "2115061002", "i"); err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+
+	if err := s.Forms().Archive(ctx, archived.ID, s.clock.Now()); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	details, err := s.Forms().ListDetails(ctx, 0, false)
+
+	if err != nil {
+		t.Fatalf("list details: %v", err)
+	}
+
+	if len(details) != 1 {
+		t.Fatalf("listed %d forms, want 1", len(details))
+	}
+
+	got := details[0]
+
+	if got.FormID != form.ID || got.TotalParticipant != 3 || got.Hadir != 1 || got.Izin != 1 || got.TanpaKeterangan != 1 || got.ArchivedAt != nil {
+		t.Errorf("details %+v, want form %d with 3 participants, 1 hadir, 1 izin, 1 tanpa keterangan", got, form.ID)
+	}
+
+	details, err = s.Forms().ListDetails(ctx, 0, true)
+
+	if err != nil {
+		t.Fatalf("list details with archived: %v", err)
+	}
+
+	if len(details) != 2 || details[1].FormID != archived.ID || details[1].ArchivedAt == nil {
+		t.Errorf("details with archived %+v, want the archived form last with archivedAt", details)
+	}
+
+	details, err = s.Forms().ListDetails(ctx, 1, true)
+
+	if err != nil {
+		t.Fatalf("list details with limit: %v", err)
+	}
+
+	if len(details) != 1 || details[0].FormID != form.ID {
+		t.Errorf("details with limit 1 %+v, want only form %d", details, form.ID)
+	}
+}
+
+func testAbsentListDuplicate(t *testing.T, ctx context.Context, s testStore) {
+	createMember(t, ctx, s, "2115061001", "Andi", 0)
+
+	form := createForm(t, ctx, s, "Rapat")
+
+	createAbsentList(t, ctx, s, form.ID, "2115061001")
+
+	err := s.AbsentLists().CreateInBatches(ctx, []models.AbsentList{{FormAbsensiID: form.ID, NPM: "2115061001"}}, 100)
+
+	if !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("create a second row: got %v, want ErrDuplicate", err)
+	}
+
+	absentLists, err := s.AbsentLists().ListByForm(ctx, form.ID)
+
+	if err != nil {
+		t.Fatalf("list by form: %v", err)
+	}
+
+	if len(absentLists) != 1 || absentLists[0].Keterangan != "?" {
+		t.Errorf("absent list %+v, want one unfilled row", absentLists)
+	}
+}
+
+func testAbsentListUpdateKeterangan(t *testing.T, ctx context.Context, s testStore) {
+	createMember(t, ctx, s, "2115061001", "Andi", 0)
+
+	form := createForm(t, ctx, s, "Rapat")
+
+	createAbsentList(t, ctx, s, form.ID, "2115061001")
+
+	if err := s.AbsentLists().UpdateKeterangan(ctx, form.ID, "2115061001", "h"); err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+
+	if err := s.AbsentLists().UpdateKeterangan(ctx, form.ID, "2115061999", "h"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("fill for a non participant: got %v, want ErrNotFound", err)
+	}
+
+	absentList, err := s.AbsentLists().Find(ctx, form.ID, "2115061001")
+
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	if absentList.Keterangan != "h" {
+		t.Errorf("keterangan %q, want h", absentList.Keterangan)
+	}
+
+	if _, err := s.AbsentLists().Find(ctx, form.ID, "2115061999"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find a non participant: got %v, want ErrNotFound", err)
+	}
+}
+
+func testAbsentListResult(t *testing.T, ctx context.Context, s testStore) {
+	s.addDepartemen(t, models.Departemen{ID: 4, Nama: "Kominfo"})
+
+	createMember(t, ctx, s, "2115061001", "Andi", 4)
+
+	form := createForm(t, ctx, s, "Rapat")
+
+	createAbsentList(t, ctx, s, form.ID, "2115061001")
+
+	result, err := s.AbsentLists().ListResult(ctx, form.ID)
+
+	if err != nil {
+		t.Fatalf("list result: %v", err)
+	}
+
+	want := models.ReturnedAbsentList{NPM: "2115061001", Keterangan: "?", Nama: "Andi", NamaDepartemen: "Kominfo"}
+
+	if len(result) != 1 {
+		t.Fatalf("result has %d rows, want 1", len(result))
+	}
+
+	result[0].UpdatedAt = time.Time{}
+
+	if result[0] != want {
+		t.Errorf("result %+v, want %+v", result[0], want)
+	}
+}
+
+func testAbsentListArchived(t *testing.T, ctx context.Context, s testStore) {
+	s.addDepartemen(t, models.Departemen{ID: 4, Nama: "Kominfo"})
+
+	createMember(t, ctx, s, "2115061001", "Andi", 4)
+
+	form := createForm(t, ctx, s, "Rapat")
+
+	createAbsentList(t, ctx, s, form.ID, "2115061001")
+
+	if err := s.Forms().Archive(ctx, form.ID, s.clock.Now()); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	if _, err := s.AbsentLists().Find(ctx, form.ID, "2115061001"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find: got %v, want ErrNotFound", err)
+	}
+
+	if err := s.AbsentLists().UpdateKeterangan(ctx, form.ID, "2115061001", "h"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("fill: got %v, want ErrNotFound", err)
+	}
+
+	if absentLists, err := s.AbsentLists().ListByForm(ctx, form.ID); err != nil || len(absentLists) != 0 {
+		t.Errorf("list by form: got %d rows and %v, want none", len(absentLists), err)
+	}
+
+	if result, err := s.AbsentLists().ListResult(ctx, form.ID); err != nil || len(result) != 0 {
+		t.Errorf("list result: got %d rows and %v, want none", len(result), err)
+	}
+
+	// the export still shows every stored row, marked as archived
+	history, err := s.AbsentLists().ListHistory(ctx, "2115061001")
+
+	if err != nil {
+		t.Fatalf("list history: %v", err)
+	}
+
+	if len(history) != 1 || history[0].FormAbsensiID != form.ID || history[0].ArchivedAt == nil {
+		t.Errorf("history %+v, want the archived form with archivedAt", history)
+	}
+
+	if err := s.Forms().Restore(ctx, form.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	if absentList, err := s.AbsentLists().Find(ctx, form.ID, "2115061001"); err != nil || absentList.Keterangan != "?" {
+		t.Errorf("find after restore: got %+v and %v, want the unfilled row", absentList, err)
+	}
+}
+
+func testAbsentListDeleteByForm(t *testing.T, ctx context.Context, s testStore) {
+	createMember(t, ctx, s, "2115061001", "Andi", 0)
+
+	form := createForm(t, ctx, s, "Rapat")
+	other := createForm(t, ctx, s, "Piket")
+
+	createAbsentList(t, ctx, s, form.ID, "2115061001")
+	createAbsentList(t, ctx, s, other.ID, "2115061001")
+
+	if err := s.AbsentLists().DeleteByForm(ctx, form.ID); err != nil {
+		t.Fatalf("delete by form: %v", err)
+	}
+
+	if absentLists, err := s.AbsentLists().ListByForm(ctx, form.ID); err != nil || len(absentLists) != 0 {
+		t.Errorf("list deleted form: got %d rows and %v, want none", len(absentLists), err)
+	}
+
+	if absentLists, err := s.AbsentLists().ListByForm(ctx, other.ID); err != nil || len(absentLists) != 1 {
+		t.Errorf("list other form: got %d rows and %v, want 1", len(absentLists), err)
+	}
+
+	// the participants of a form can be generated again
+	createAbsentList(t, ctx, s, form.ID, "2115061001")
+}
+
+func testEraseMember(t *testing.T, ctx context.Context, s testStore) {
+	s.addDepartemen(t, models.Departemen{ID: 4, Nama: "Kominfo"})
+
+	createMember(t, ctx, s, "2115061001", "Andi", 4)
+	createMember(t, ctx, s, "erased-1", "", 0)
+
+	first := createForm(t, ctx, s, "Rapat")
+	s.clock.Advance(time.Minute)
+	second := createForm(t, ctx, s, "Piket")
+
+	createAbsentList(t, ctx, s, first.ID, "2115061001")
+	s.clock.Advance(time.Minute)
+	createAbsentList(t, ctx, s, second.ID, "2115061001")
+
+	if err := s.AbsentLists().ReplaceNPM(ctx, "2115061001", "erased-1"); err != nil {
+		t.Fatalf("replace absent list npm: %v", err)
+	}
+
+	if err := s.Members().DeletePengurus(ctx, "2115061001"); err != nil {
+		t.Fatalf("delete pengurus: %v", err)
+	}
+
+	if history, err := s.AbsentLists().ListHistory(ctx, "2115061001"); err != nil || len(history) != 0 {
+		t.Errorf("history of the old npm: got %d rows and %v, want none", len(history), err)
+	}
+
+	history, err := s.AbsentLists().ListHistory(ctx, "erased-1")
+
+	if err != nil {
+		t.Fatalf("list history: %v", err)
+	}
+
+	if len(history) != 2 || history[0].FormAbsensiID != first.ID || history[1].FormAbsensiID != second.ID {
+		t.Errorf("history %+v, want forms %d and %d oldest first", history, first.ID, second.ID)
+	}
+
+	if _, err := s.Members().FindPengurus(ctx, "2115061001"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find pengurus of the old npm: got %v, want ErrNotFound", err)
+	}
+
+	if pengurus, err := s.Members().ListPengurus(ctx, 4); err != nil || len(pengurus) != 0 {
+		t.Errorf("list pengurus: got %+v and %v, want none", pengurus, err)
+	}
+
+	result, err := s.AbsentLists().ListResult(ctx, first.ID)
+
+	if err != nil {
+		t.Fatalf("list result: %v", err)
+	}
+
+	if len(result) != 1 || result[0].NPM != "erased-1" || result[0].NamaDepartemen != "" {
+		t.Errorf("result %+v, want only erased-1 without departemen", result)
+	}
+}
+
+func testUser(t *testing.T, ctx context.Context, s testStore) {
+	createMember(t, ctx, s, "2115061001", "Andi", 0)
+
+	if err := s.Users().Create(ctx, &models.User{NPM: "2115061001", Password: "secret"}); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	if err := s.Users().Create(ctx, &models.User{NPM: "2115061001", Password: "other"}); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("create a second user: got %v, want ErrDuplicate", err)
+	}
+
+	user, err := s.Users().FindByNPM(ctx, "2115061001")
+
+	if err != nil || user.Password != "secret" {
+		t.Errorf("find user: got %+v and %v, want the first user", user, err)
+	}
+
+	if err := s.Users().DeleteByNPM(ctx, "2115061001"); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+
+	if _, err := s.Users().FindByNPM(ctx, "2115061001"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find deleted user: got %v, want ErrNotFound", err)
+	}
+}
+
+func testTransactionRollback(t *testing.T, ctx context.Context, s testStore) {
+	errAbort := errors.New("abort")
+
+	var created models.FormAbsensi
+
+	err := s.Transaction(ctx, func(tx repository.Store) error {
+		created = models.FormAbsensi{Title: "Rapat", StartAt: s.clock.Now(), FinishAt: s.clock.Now().Add(time.Hour)}
+
+		if err := tx.Forms().Create(ctx, &created); err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("transaction: got %v, want the callback error", err)
+	}
+
+	if _, err := s.Forms().FindByID(ctx, created.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find form of the rolled back transaction: got %v, want ErrNotFound", err)
+	}
+
+	err = s.Transaction(ctx, func(tx repository.Store) error {
+		created = models.FormAbsensi{Title: "Rapat", StartAt: s.clock.Now(), FinishAt: s.clock.Now().Add(time.Hour)}
+
+		return tx.Forms().Create(ctx, &created)
+	})
+
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+
+	if _, err := s.Forms().FindByID(ctx, created.ID); err != nil {
+		t.Errorf("find form of the committed transaction: %v", err)
+	}
+}
+
+func testIdempotencyKey(t *testing.T, ctx context.Context, s testStore) {
+	now := s.clock.Now()
+
+	reserve := func(key string, scope string, expiresAt time.Time) bool {
+		t.Helper()
+
+		reserved, err := s.IdempotencyKeys().Reserve(ctx, &models.IdempotencyKey{
+			Key:         key,
+			Scope:       scope,
+			RequestHash: "hash",
+			ExpiresAt:   expiresAt,
+		})
+
+		if err != nil {
+			t.Fatalf("reserve %s in %s: %v", key, scope, err)
+		}
+
+		return reserved
+	}
+
+	if !reserve("a", "POST /admin/absensi auth:1", now.Add(time.Hour)) {
+		t.Fatal("first reserve was refused")
+	}
+
+	if reserve("a", "POST /admin/absensi auth:1", now.Add(time.Hour)) {
+		t.Error("second reserve of the same key and scope was accepted")
+	}
+
+	if !reserve("a", "POST /admin/absensi auth:2", now.Add(time.Hour)) {
+		t.Error("reserve of the same key in another scope was refused")
+	}
+
+	if err := s.IdempotencyKeys().Complete(ctx, "a", "POST /admin/absensi auth:1", 201, "{}", []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	if err := s.IdempotencyKeys().Release(ctx, "a", "POST /admin/absensi auth:1"); err != nil {
+		t.Fatalf("release completed key: %v", err)
+	}
+
+	stored, err := s.IdempotencyKeys().Find(ctx, "a", "POST /admin/absensi auth:1")
+
+	if err != nil {
+		t.Fatalf("find completed key: %v", err)
+	}
+
+	if !stored.Completed || stored.StatusCode != 201 || string(stored.Body) != `{"ok":true}` {
+		t.Errorf("stored key %+v, want the completed response", stored)
+	}
+
+	if err := s.IdempotencyKeys().Release(ctx, "a", "POST /admin/absensi auth:2"); err != nil {
+		t.Fatalf("release pending key: %v", err)
+	}
+
+	if _, err := s.IdempotencyKeys().Find(ctx, "a", "POST /admin/absensi auth:2"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find released key: got %v, want ErrNotFound", err)
+	}
+
+	reserve("b", "POST /admin/absensi auth:1", now.Add(-time.Minute))
+
+	if err := s.IdempotencyKeys().DeleteExpired(ctx, now); err != nil {
+		t.Fatalf("delete expired: %v", err)
+	}
+
+	if _, err := s.IdempotencyKeys().Find(ctx, "b", "POST /admin/absensi auth:1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("find expired key: got %v, want ErrNotFound", err)
+	}
+
+	if _, err := s.IdempotencyKeys().Find(ctx, "a", "POST /admin/absensi auth:1"); err != nil {
+		t.Errorf("find unexpired key: %v", err)
+	}
+}
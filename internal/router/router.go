@@ -1,38 +1,91 @@
 package router
 
 import (
+	"himatro-api/internal/clock"
+	"himatro-api/internal/config"
+	"himatro-api/internal/controller"
 	"himatro-api/internal/handler"
+	"himatro-api/internal/logger"
+	"himatro-api/internal/metrics"
 	"himatro-api/internal/middleware"
+	"himatro-api/internal/migration"
+	"himatro-api/internal/repository"
+	"himatro-api/internal/tracing"
+	"log/slog"
+	"time"
 
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 
 	"github.com/labstack/echo/v4"
 )
 
-func Router() *echo.Echo {
+// legacyAPIDeprecatedAt is when the unversioned routes were superseded by /v1.
+var legacyAPIDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// routes holds what the route tables need to build their routes.
+type routes struct {
+	h            *handler.Handler
+	requireLogin echo.MiddlewareFunc
+	idempotency  echo.MiddlewareFunc
+}
+
+// Router builds the API served from the given store. Every schedule and token
+// expiry check uses clk, and the controllers log to log outside of requests.
+func Router(store repository.Store, clk clock.Clock, log *slog.Logger) *echo.Echo {
+	schemaVersion, err := migration.Latest(config.Get().Database.Driver)
+
+	if err != nil {
+		log.Error("Embedded migrations are invalid, /readyz will fail", logger.ErrorKey, err)
+	}
+
+	r := routes{
+		h: handler.New(
+			clk,
+			controller.NewAbsentController(store, clk, log),
+			controller.NewAuthController(store.Users(), clk, log),
+			controller.NewMemberController(store, clk, log),
+			controller.NewHealthController(store, schemaVersion, log),
+		),
+		requireLogin: middleware.RequireLogin(clk),
+		idempotency:  middleware.Idempotency(controller.NewIdempotencyController(store.IdempotencyKeys(), clk, log)),
+	}
+
 	e := echo.New()
+	e.HTTPErrorHandler = handler.ErrorHandler
 
+	e.Use(otelecho.Middleware(tracing.ServiceName, otelecho.WithSkipper(func(c echo.Context) bool {
+		return c.Path() == "/metrics"
+	})))
+	e.Use(middleware.RequestID())
+	e.Use(metrics.Middleware())
 	e.Use(middleware.RequestLogger())
-	e.Use(echoMiddleware.CORS())
+	e.Use(echoMiddleware.BodyLimit(config.Get().Server.BodyLimit))
+	e.Use(echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
+		ExposeHeaders: []string{
+			echo.HeaderXRequestID,
+			middleware.HeaderDeprecation,
+			middleware.HeaderSunset,
+			middleware.HeaderLink,
+			handler.HeaderETag,
+			middleware.HeaderIdempotentReplayed,
+		},
+	}))
+
+	if config.Get().Metrics.Addr == "" && config.Get().Metrics.APIKey != "" {
+		e.GET("/metrics", handler.Metrics, middleware.RequireMetricsKey)
+	} else if config.Get().Metrics.Addr == "" {
+		logger.Default().Warn("Neither METRICS_ADDR nor METRICS_API_KEY is set, /metrics is disabled")
+	}
 
 	e.GET("/", handler.HomeGet)
-	e.POST("/login", handler.Login)
-
-	e.GET("/absensi/:absentID", handler.CheckAbsentForm)
-	e.POST("/absensi/:absentID", handler.FillAbsentForm)
-	e.PATCH("/absensi/:absentID", handler.UpdateAbsentListByAttendant)
-
-	e.GET("/absensi/:absentID/result", handler.GetAbsentResult)
-
-	e.GET("/admin", handler.Admin)
-	e.GET("/admin/absensi", handler.GetAbsentFormsDetails, middleware.RequireLogin)
-	e.POST("/admin/absensi", handler.InitAbsent, middleware.RequireLogin)
-	e.PATCH("/admin/absensi/:absentID/title", handler.UpdateFormTitle, middleware.RequireLogin)
-	e.PATCH("/admin/absensi/:absentID/participant", handler.UpdateFormParticipant, middleware.RequireLogin)
-	e.PATCH("/admin/absensi/:absentID/startAt", handler.UpdateFormStartAt, middleware.RequireLogin)
-	e.PATCH("/admin/absensi/:absentID/finishAt", handler.UpdateAbsentFormFinishAt, middleware.RequireLogin)
-	e.PATCH("/admin/absensi/:absentID/attendanceImageProof", handler.UpdateAbsentFormAttendanceImageProof, middleware.RequireLogin)
-	e.PATCH("/admin/absensi/:absentID/execuseImageProof", handler.UpdateAbsentFormExecuseImageProof, middleware.RequireLogin)
+	e.GET("/healthz", handler.Liveness)
+	e.GET("/readyz", r.h.Readiness)
+
+	registerV1(e.Group("/v1"), r)
+
+	// The unversioned routes are kept as aliases of /v1 until the frontend moves over.
+	registerV1(e.Group(""), r, middleware.Deprecated(legacyAPIDeprecatedAt, config.Get().API.LegacySunsetDate(), "/v1"))
 
 	return e
 }
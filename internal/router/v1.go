@@ -0,0 +1,42 @@
+package router
+
+import (
+	"himatro-api/internal/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// registerV1 adds the v1 routes to g. The given middleware run before the route
+// middleware, so the same table also serves the deprecated unversioned aliases.
+// Breaking changes to a payload belong in a new registerV2, never here.
+func registerV1(g *echo.Group, r routes, m ...echo.MiddlewareFunc) {
+	with := func(route ...echo.MiddlewareFunc) []echo.MiddlewareFunc {
+		return append(append([]echo.MiddlewareFunc{}, m...), route...)
+	}
+
+	g.POST("/login", r.h.Login, with()...)
+
+	g.GET("/absensi/:absentID", r.h.CheckAbsentForm, with()...)
+	g.POST("/absensi/:absentID", r.h.FillAbsentForm, with(r.idempotency)...)
+	g.PATCH("/absensi/:absentID", r.h.UpdateAbsentListByAttendant, with()...)
+
+	g.GET("/absensi/:absentID/result", r.h.GetAbsentResult, with()...)
+
+	g.GET("/admin", handler.Admin, with()...)
+	g.GET("/admin/absensi", r.h.GetAbsentFormsDetails, with(r.requireLogin)...)
+	g.POST("/admin/absensi", r.h.InitAbsent, with(r.requireLogin, r.idempotency)...)
+	g.GET("/admin/absensi/:absentID", r.h.GetAbsentForm, with(r.requireLogin)...)
+	g.PATCH("/admin/absensi/:absentID", r.h.PatchAbsentForm, with(r.requireLogin)...)
+	g.DELETE("/admin/absensi/:absentID", r.h.ArchiveAbsentForm, with(r.requireLogin)...)
+	g.POST("/admin/absensi/:absentID/restore", r.h.RestoreAbsentForm, with(r.requireLogin)...)
+	g.PATCH("/admin/absensi/:absentID/title", r.h.UpdateFormTitle, with(r.requireLogin)...)
+	g.PATCH("/admin/absensi/:absentID/participant", r.h.UpdateFormParticipant, with(r.requireLogin)...)
+	g.PATCH("/admin/absensi/:absentID/startAt", r.h.UpdateFormStartAt, with(r.requireLogin)...)
+	g.PATCH("/admin/absensi/:absentID/finishAt", r.h.UpdateAbsentFormFinishAt, with(r.requireLogin)...)
+	g.PATCH("/admin/absensi/:absentID/attendanceImageProof", r.h.UpdateAbsentFormAttendanceImageProof, with(r.requireLogin)...)
+	g.PATCH("/admin/absensi/:absentID/execuseImageProof", r.h.UpdateAbsentFormExecuseImageProof, with(r.requireLogin)...)
+	g.PATCH("/admin/absensi/:absentID/resultVisibility", r.h.UpdateAbsentFormResultVisibility, with(r.requireLogin)...)
+
+	g.GET("/admin/members/:NPM/export", r.h.ExportMemberData, with(r.requireLogin)...)
+	g.POST("/admin/members/:NPM/erase", r.h.EraseMemberData, with(r.requireLogin)...)
+}
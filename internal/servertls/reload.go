@@ -0,0 +1,122 @@
+package servertls
+
+import (
+	"crypto/tls"
+	"himatro-api/internal/logger"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval is how often a handshake looks for changed files.
+const reloadCheckInterval = 10 * time.Second
+
+// certReloader serves the certificate in certFile and keyFile, and loads them
+// again on a handshake after either file changes, e.g. after a renewal. The files
+// are checked at most every reloadCheckInterval.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+
+	// failedModTime and statErr hold the last failure, so it is only logged
+	// again once the files change.
+	failedModTime time.Time
+	statErr       string
+}
+
+func newCertReloader(certFile string, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+
+	modTime, err := r.latestModTime()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	r.checkedAt = time.Now()
+
+	return r, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := time.Now(); now.Sub(r.checkedAt) >= reloadCheckInterval {
+		r.checkedAt = now
+		r.reload()
+	}
+
+	return r.cert, nil
+}
+
+// reload loads the files if they changed since the last load. A half written
+// renewal must not take the server down, so a failure keeps the certificate
+// loaded before, and isn't tried or logged again until the files change.
+func (r *certReloader) reload() {
+	modTime, err := r.latestModTime()
+
+	if err != nil {
+		if err.Error() != r.statErr {
+			r.statErr = err.Error()
+			logger.Default().Error("Failed to reload the TLS certificate, keeping the old one", logger.ErrorKey, err)
+		}
+
+		return
+	}
+
+	r.statErr = ""
+
+	if !modTime.After(r.modTime) || modTime.Equal(r.failedModTime) {
+		return
+	}
+
+	if err := r.load(modTime); err != nil {
+		r.failedModTime = modTime
+		logger.Default().Error("Failed to reload the TLS certificate, keeping the old one", logger.ErrorKey, err)
+	}
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if err != nil {
+		return err
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+
+	logger.Default().Info("TLS certificate loaded", "cert", r.certFile)
+
+	return nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	keyInfo, err := os.Stat(r.keyFile)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if keyInfo.ModTime().After(certInfo.ModTime()) {
+		return keyInfo.ModTime(), nil
+	}
+
+	return certInfo.ModTime(), nil
+}
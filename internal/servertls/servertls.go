@@ -0,0 +1,87 @@
+// Package servertls terminates HTTPS in the server itself, from certificate
+// files or from an ACME server such as Let's Encrypt.
+package servertls
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type Options struct {
+	// Either CertFile and KeyFile, reloaded when they change...
+	CertFile string
+	KeyFile  string
+
+	// ...or ACMEDomains, whose certificates are kept in ACMECacheDir.
+	ACMEDomains      []string
+	ACMEEmail        string
+	ACMECacheDir     string
+	ACMEDirectoryURL string // empty for Let's Encrypt production
+
+	// HTTPSPort is added to redirects when it isn't 443.
+	HTTPSPort string
+}
+
+// Setup returns the TLS config of the HTTPS listener, with HTTP/2 enabled, and
+// the handler of the plain HTTP listener: it answers the ACME challenges and
+// redirects everything else to HTTPS.
+func Setup(opts Options) (*tls.Config, http.Handler, error) {
+	redirect := redirectHandler(opts.HTTPSPort)
+
+	if len(opts.ACMEDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(opts.ACMEDomains...),
+			Cache:      autocert.DirCache(opts.ACMECacheDir),
+			Email:      opts.ACMEEmail,
+		}
+
+		if opts.ACMEDirectoryURL != "" {
+			manager.Client = &acme.Client{DirectoryURL: opts.ACMEDirectoryURL}
+		}
+
+		tlsConfig := manager.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+
+		return tlsConfig, manager.HTTPHandler(redirect), nil
+	}
+
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, nil, errors.New("either certificate files or ACME domains are required")
+	}
+
+	reloader, err := newCertReloader(opts.CertFile, opts.KeyFile)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	return tlsConfig, redirect, nil
+}
+
+func redirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
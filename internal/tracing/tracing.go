@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ServiceName = "himatro-api"
+
+type Options struct {
+	Enabled     bool
+	Endpoint    string  // host:port of the OTLP/HTTP collector
+	Insecure    bool    // send over plain HTTP, e.g. to a local collector
+	SampleRatio float64 // fraction of new traces to record, between 0 and 1
+}
+
+// Init installs the global tracer provider. When tracing is disabled it keeps
+// the no-op provider, so spans cost nothing. The returned function flushes the
+// remaining spans and must be called before the program exits.
+func Init(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	if !opts.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOpts := []otlptracehttp.Option{}
+
+	if opts.Endpoint != "" {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+	}
+
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(ServiceName).Start(ctx, name, opts...)
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskNPM keeps the first four and the last two characters of an NPM
+// and replaces the rest with asterisks, e.g. 1915061056 -> 1915****56
+func MaskNPM(NPM string) string {
+	if len(NPM) <= 6 {
+		return strings.Repeat("*", len(NPM))
+	}
+
+	return NPM[:4] + strings.Repeat("*", len(NPM)-6) + NPM[len(NPM)-2:]
+}
+
+// NameInitials turns a full name into its initials, e.g. Lucky Akbar -> L. A.
+func NameInitials(name string) string {
+	initials := []string{}
+
+	for _, word := range strings.Fields(name) {
+		r, _ := utf8.DecodeRuneInString(word)
+		initials = append(initials, strings.ToUpper(string(r))+".")
+	}
+
+	return strings.Join(initials, " ")
+}
@@ -0,0 +1,46 @@
+package util
+
+import "encoding/json"
+
+// MergePatch applies a JSON merge patch (RFC 7396) to the target document:
+// members of the patch replace the ones in the target, null removes them.
+func MergePatch(target []byte, patch []byte) ([]byte, error) {
+	var targetValue interface{}
+
+	if err := json.Unmarshal(target, &targetValue); err != nil {
+		return nil, err
+	}
+
+	var patchValue interface{}
+
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergePatch(targetValue, patchValue))
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
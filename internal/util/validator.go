@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"himatro-api/internal/logger"
 
 	validator "github.com/go-playground/validator/v10"
 )
@@ -12,7 +13,7 @@ func ExtractValidationErrorMsg(err error) []string {
 	var errorStack []string
 
 	for _, err := range err.(validator.ValidationErrors) {
-		LogErr("WARN", fmt.Sprintf("%s is invalid, status: %s, got: '%s'.", err.StructField(), err.Tag(), err.Value()), "")
+		logger.Default().Debug("JSON payload field is invalid", "field", err.StructField(), "tag", err.Tag(), "value", err.Value())
 		errorStack = append(errorStack, fmt.Sprintf("%s is invalid, status: %s, got: '%s'.", err.StructField(), err.Tag(), err.Value()))
 	}
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"himatro-api/internal/console"
+	_ "time/tzdata" // form time zones work on images without a zone database
 )
 
 func main() {